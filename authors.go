@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// authorsText returns content of AUTHORS file which lists copyright
+// holders of the project.
+func authorsText(project string, holders []string) string {
+	var buf bytes.Buffer
+	if project != "" && project != DefaultValue {
+		fmt.Fprintf(&buf, "# This is the list of %s authors for copyright purposes.\n", project)
+	} else {
+		buf.WriteString("# This is the list of authors for copyright purposes.\n")
+	}
+	buf.WriteString("# See LICENSE for the terms under which they license the project.\n\n")
+
+	for _, h := range holders {
+		buf.WriteString(h + "\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// corpus is the snapshot of LICENSE templates bundled in the binary.
+// It is used when GitHub API is not available (e.g., no network).
+//
+//go:embed corpus
+var corpus embed.FS
+
+// frontMatterSep separates front matter (YAML metadata) and body of
+// a template. The format is the same as http://choosealicense.com/
+// (https://github.com/github/choosealicense.com/tree/gh-pages/_licenses)
+const frontMatterSep = "---\n"
+
+// frontMatter is metadata of LICENSE template.
+type frontMatter struct {
+	Title       string   `yaml:"title"`
+	SPDXID      string   `yaml:"spdx-id"`
+	Featured    bool     `yaml:"featured"`
+	Description string   `yaml:"description"`
+	How         string   `yaml:"how"`
+	Permissions []string `yaml:"permissions"`
+	Conditions  []string `yaml:"conditions"`
+	Limitations []string `yaml:"limitations"`
+}
+
+// fsSource is LicenseSource which reads LICENSE templates from fs.FS.
+// Each template is named KEY.txt and it may start with front matter.
+type fsSource struct {
+	fsys fs.FS
+
+	// bundled is true for the snapshot bundled in the binary
+	bundled bool
+}
+
+// newBundledSource returns LICENSE source of bundled corpus.
+func newBundledSource() *fsSource {
+	return newEmbedSource("corpus/licenses")
+}
+
+// newBundledExceptions returns source of LICENSE exceptions
+// (e.g., Classpath exception) in bundled corpus.
+func newBundledExceptions() *fsSource {
+	return newEmbedSource("corpus/exceptions")
+}
+
+// newEmbedSource returns source of templates in dir of corpus.
+func newEmbedSource(dir string) *fsSource {
+	fsys, err := fs.Sub(corpus, dir)
+	if err != nil {
+		// Should not reach here
+		panic(err)
+	}
+	return &fsSource{fsys: fsys, bundled: true}
+}
+
+// List returns all LICENSE templates in the file system.
+func (s *fsSource) List() ([]*License, error) {
+	files, err := fs.Glob(s.fsys, "*.txt")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	list := make([]*License, 0, len(files))
+	for _, f := range files {
+		key := strings.TrimSuffix(path.Base(f), ".txt")
+		l, err := s.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+
+	return list, nil
+}
+
+// Get reads LICENSE template by key.
+func (s *fsSource) Get(key string) (*License, error) {
+	b, err := fs.ReadFile(s.fsys, key+".txt")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, newNotFoundError("license %q is not found", key)
+		}
+		return nil, err
+	}
+
+	l, err := parseTemplate(key, b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %q: %s", key, err.Error())
+	}
+	return l, nil
+}
+
+// parseTemplate parses LICENSE template which may have front matter.
+// If it doesn't have front matter, key is used as name.
+func parseTemplate(key string, b []byte) (*License, error) {
+	l := &License{
+		Key:  key,
+		Name: key,
+	}
+
+	if !bytes.HasPrefix(b, []byte(frontMatterSep)) {
+		l.Body = string(b)
+		return l, nil
+	}
+
+	parts := bytes.SplitN(b[len(frontMatterSep):], []byte("\n"+frontMatterSep), 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("front matter is not closed")
+	}
+
+	var fm frontMatter
+	if err := yaml.Unmarshal(parts[0], &fm); err != nil {
+		return nil, err
+	}
+
+	if fm.Title != "" {
+		l.Name = fm.Title
+	}
+	l.SPDXID = fm.SPDXID
+	l.Featured = fm.Featured
+	l.Description = fm.Description
+	l.Implementation = fm.How
+	l.Permissions = fm.Permissions
+	l.Conditions = fm.Conditions
+	l.Limitations = fm.Limitations
+	l.Body = strings.TrimLeft(string(parts[1]), "\n")
+
+	return l, nil
+}
+
+// newDirSource returns LICENSE source of templates in local directory.
+// The directory doesn't need to exist.
+func newDirSource(dir string) *fsSource {
+	return &fsSource{fsys: os.DirFS(dir)}
+}
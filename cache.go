@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -15,10 +16,30 @@ const (
 	// CacheDir is directory for caching LICENSE files
 	CacheDirName = ".lcns"
 
-	// CacheDuration is duration for storing cache
+	// CacheDuration is default duration for storing cache
 	CacheDuration = 30 * 24 * time.Hour
 )
 
+// cacheFiles returns cache files of key in path. Cache file is named
+// key + "-" + Unix time, so only such names are matched exactly
+// (e.g., cache of "gpl-3.0" is not cache of "gpl").
+func cacheFiles(key, path string) ([]string, error) {
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	nameReg := regexp.MustCompile(`^` + regexp.QuoteMeta(key) + `-\d+$`)
+
+	var files []string
+	for _, info := range infos {
+		if !info.IsDir() && nameReg.MatchString(info.Name()) {
+			files = append(files, filepath.Join(path, info.Name()))
+		}
+	}
+	return files, nil
+}
+
 // setCache saves body as file (named key + Unix time) in provided
 // path. Any errors that occur are returned.
 func setCache(body, key, path string) error {
@@ -48,26 +69,26 @@ func setCache(body, key, path string) error {
 	return err
 }
 
-// getCache read cache contents from provided path.
-// Any errors that occur are returned.
-func getCache(key, path string) (string, error) {
-	cacheFiles, err := filepath.Glob(filepath.Join(path, key+"-"+"*"))
+// getCache read cache contents from provided path. Cache older than
+// duration is not used. Any errors that occur are returned.
+func getCache(key, path string, duration time.Duration) (string, error) {
+	files, _ := cacheFiles(key, path)
 
 	// Check cache file is exist or not
-	if len(cacheFiles) == 0 {
+	if len(files) == 0 {
 		return "", fmt.Errorf("cache file is not exist in %s", path)
 	}
 
 	// Check cache is latest or not
-	cache := cacheFiles[0]
-	createdUnix, err := strconv.Atoi(strings.Split(cache, "-")[len(strings.Split(cache, "-"))-1])
+	cache := files[0]
+	createdUnix, err := strconv.Atoi(cache[strings.LastIndex(cache, "-")+1:])
 	if err != nil {
 		return "", fmt.Errorf("invalid cache file name: %s", cache)
 	}
 	createdTime := time.Unix(int64(createdUnix), 0)
 	Debugf("Cache was created at %s", createdTime.String())
 
-	if time.Now().Sub(createdTime) > CacheDuration {
+	if time.Now().Sub(createdTime) > duration {
 		return "", fmt.Errorf("cache file is old")
 	}
 
@@ -80,9 +101,9 @@ func getCache(key, path string) (string, error) {
 
 // cleanCache deletes old cache files. Any errors that occur are returned.
 func cleanCache(key, path string) {
-	oldCacheFiles, err := filepath.Glob(filepath.Join(path, key+"-"+"*"))
+	oldCacheFiles, err := cacheFiles(key, path)
 	if err != nil {
-		Debugf("Failed to glob cache files: %s", err.Error())
+		Debugf("Failed to list cache files: %s", err.Error())
 	}
 
 	for _, of := range oldCacheFiles {
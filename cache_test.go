@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetCache_exactKey(t *testing.T) {
+	dir := t.TempDir()
+	if err := setCache("GPL-3.0", "gpl-3.0", dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cache of "gpl-3.0" is not cache of "gpl"
+	if body, err := getCache("gpl", dir, time.Hour); err == nil {
+		t.Errorf("expected cache of gpl not to exist: %q", body)
+	}
+
+	// Saving cache of "gpl" must not delete cache of "gpl-3.0"
+	if err := setCache("GPL", "gpl", dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expected := range map[string]string{"gpl": "GPL", "gpl-3.0": "GPL-3.0"} {
+		body, err := getCache(key, dir, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to get cache of %s: %s", key, err)
+		}
+		if body != expected {
+			t.Errorf("expected %q to eq %q", body, expected)
+		}
+	}
+}
+
+func TestGetLicense_localSource(t *testing.T) {
+	cacheDir, templatesDir := t.TempDir(), t.TempDir()
+	if err := setCache("cached", "mit", cacheDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(templatesDir, "mit.txt"), []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cli := &CLI{source: chainSource{newDirSource(templatesDir), testSource}}
+	o := &options{cacheDir: cacheDir, cacheDuration: time.Hour}
+
+	// Custom template wins over cache of remote source
+	license, cached, err := cli.getLicense("mit", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached || license.Body != "custom" {
+		t.Errorf("expected %q (cached: %t) to eq %q", license.Body, cached, "custom")
+	}
+
+	// LICENSE of local source is never cached
+	cli.source = chainSource{newBundledSource()}
+	if _, _, err := cli.getLicense("unlicense", o); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := cacheFiles("unlicense", cacheDir); len(files) != 0 {
+		t.Errorf("expected no cache of unlicense: %v", files)
+	}
+}
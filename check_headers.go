@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Status of source file reported by check-headers.
+const (
+	HeaderMissing  = "missing"
+	HeaderMismatch = "mismatch"
+)
+
+// headerIssue is the problem of license header in source file.
+type headerIssue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Status   string `json:"status"`
+	Expected string `json:"expected"`
+	Found    string `json:"found,omitempty"`
+	Message  string `json:"message"`
+}
+
+// headerNotice returns LICENSE key of the notice (see headerTemplates)
+// found in the header of content.
+func headerNotice(content []byte) (string, bool) {
+	var lines []string
+	for _, l := range headLines(content, HeaderScanLines) {
+		lines = append(lines, uncomment(l))
+	}
+	words := normalizeText(strings.Join(lines, "\n"))
+
+	best, score := "", 0.0
+	for key, tmpl := range headerTemplates {
+		if s := containment(normalizeText(tmpl), words); s > score {
+			best, score = key, s
+		}
+	}
+
+	if score < MatchThreshold {
+		return "", false
+	}
+	return best, true
+}
+
+// checkHeader checks license header of content. It returns nil
+// if content carries the notice or SPDX-License-Identifier of license.
+func checkHeader(path string, content []byte, license *License) *headerIssue {
+	if generatedReg.Match(content) {
+		return nil
+	}
+
+	expected := currentSPDXID(license.SPDXID, false)
+	if expected == "" {
+		expected = license.Key
+	}
+
+	if id, ok := findSPDXID(content); ok {
+		if sameSPDXID(id, license.SPDXID) {
+			return nil
+		}
+
+		return &headerIssue{
+			File:     path,
+			Line:     spdxLine(content),
+			Status:   HeaderMismatch,
+			Expected: expected,
+			Found:    id,
+			Message:  fmt.Sprintf("%s %s is different from LICENSE (%s)", SPDXTag, id, expected),
+		}
+	}
+
+	if key, ok := headerNotice(content); ok {
+		if key == license.Key {
+			return nil
+		}
+
+		found := key
+		if l, err := newBundledSource().Get(key); err == nil && l.SPDXID != "" {
+			found = currentSPDXID(l.SPDXID, false)
+		}
+		return &headerIssue{
+			File:     path,
+			Line:     1,
+			Status:   HeaderMismatch,
+			Expected: expected,
+			Found:    found,
+			Message:  fmt.Sprintf("license header is %s but LICENSE is %s", found, expected),
+		}
+	}
+
+	msg := fmt.Sprintf("%s %s is missing", SPDXTag, expected)
+	if _, ok := headerTemplates[license.Key]; ok {
+		msg = fmt.Sprintf("license header of %s is missing", expected)
+	}
+	return &headerIssue{
+		File:     path,
+		Line:     1,
+		Status:   HeaderMissing,
+		Expected: expected,
+		Message:  msg,
+	}
+}
+
+// spdxLine returns line number (1-origin) of SPDX-License-Identifier.
+func spdxLine(content []byte) int {
+	for i, l := range headLines(content, HeaderScanLines) {
+		if spdxTagReg.MatchString(l) {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// detectRepoLicense returns LICENSE of the repository in dir by comparing
+// LICENSE file with template bodies.
+func (cli *CLI) detectRepoLicense(dir string, o *options) (*License, error) {
+	corpus, err := cli.loadCorpus(o)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := detectLicense(dir, corpus, cli.loadExceptions())
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Key == "" {
+		return nil, fmt.Errorf("cannot determine LICENSE of %s: use -key option", d.File)
+	}
+
+	for _, l := range corpus {
+		if l.Key == d.Key {
+			return l, nil
+		}
+	}
+	return nil, fmt.Errorf("LICENSE %q is not found", d.Key)
+}
+
+// printHeaderIssues prints issues in format (text, json or github).
+func (cli *CLI) printHeaderIssues(issues []*headerIssue, format string) error {
+	switch format {
+	case "text":
+		for _, i := range issues {
+			fmt.Fprintf(cli.outStream, "%s:%d: %s\n", i.File, i.Line, i.Message)
+		}
+	case "json":
+		if issues == nil {
+			issues = []*headerIssue{}
+		}
+		enc := json.NewEncoder(cli.outStream)
+		enc.SetIndent("", "  ")
+		return enc.Encode(issues)
+	case "github":
+		// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions
+		for _, i := range issues {
+			fmt.Fprintf(cli.outStream, "::error file=%s,line=%d,title=License header::%s\n",
+				filepath.ToSlash(i.File), i.Line, i.Message)
+		}
+	default:
+		return fmt.Errorf("unknown format %q: must be text, json or github", format)
+	}
+	return nil
+}
+
+// runCheckHeaders runs `license check-headers` command. It reports
+// source files which don't carry license header of the repository.
+func (cli *CLI) runCheckHeaders(args []string) int {
+	var (
+		key    string
+		format string
+		o      options
+	)
+
+	flags := flag.NewFlagSet(Name+" check-headers", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, checkHeadersHelpText)
+	}
+
+	flags.StringVar(&key, "key", "", "")
+	flags.StringVar(&format, "format", "text", "")
+	o.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	if key == "" {
+		key = o.config.License
+	}
+
+	var license *License
+	if key != "" {
+		l, _, err := cli.getLicense(strings.ToLower(key), &o)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to get LICENSE: %s\n", err.Error())
+			return ExitCodeError
+		}
+		license = l
+	} else {
+		l, err := cli.detectRepoLicense(paths[0], &o)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to detect LICENSE: %s\n", err.Error())
+			return ExitCodeError
+		}
+		license = l
+	}
+
+	if license.SPDXID == "" {
+		if _, ok := headerTemplates[license.Key]; !ok {
+			fmt.Fprintf(cli.errStream, "Failed to check license header: SPDX ID of %q is unknown\n", license.Key)
+			return ExitCodeError
+		}
+	}
+	Debugf("Check license header of %s", license.Key)
+
+	var issues []*headerIssue
+	checked := 0
+	err := walkSourceFiles(paths, func(path string, style commentStyle) error {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		checked++
+		if issue := checkHeader(path, content, license); issue != nil {
+			issues = append(issues, issue)
+		}
+		return nil
+	})
+
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to check license header: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].File < issues[j].File
+	})
+
+	if err := cli.printHeaderIssues(issues, format); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to print result: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	if len(issues) > 0 {
+		fmt.Fprintf(cli.errStream, "====> %d of %d files don't have license header of %s\n", len(issues), checked, license.Key)
+		return ExitCodeCheckFailed
+	}
+
+	fmt.Fprintf(cli.errStream, "====> All %d files have license header of %s\n", checked, license.Key)
+	return ExitCodeOK
+}
+
+var checkHeadersHelpText = `Usage: license check-headers [option] [PATH...]
+
+  Check that every source file in PATH (by default, current directory)
+  carries license header of the repository. Files which miss the header
+  or SPDX-License-Identifier, or carry a different license are reported
+  and it exits with non-zero status. It's useful on CI.
+
+  LICENSE of the repository is determined by comparing LICENSE file in
+  the first PATH with LICENSE templates. Comment syntax is detected by
+  file extension.
+
+Options:
+
+  -key=KEY            LICENSE key of the repository. By default, license
+                      in config file or detected one from LICENSE file
+                      is used.
+
+  -format=FORMAT      Output format: text (default), json or github.
+                      'github' prints workflow commands of GitHub Actions
+                      to annotate files in pull requests.
+
+  Options to fetch LICENSE (e.g., -offline) are the same as generating
+  LICENSE.
+`
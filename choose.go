@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/colorstring"
+)
+
+// ruleFilter filters LICENSE by its rules (see ruleLabels).
+type ruleFilter struct {
+	// permissions are rules which LICENSE must have all of.
+	permissions []string
+
+	// conditions are rules which LICENSE must have one of.
+	conditions []string
+
+	// noConditions are rules which LICENSE must have none of.
+	noConditions []string
+}
+
+// match returns true if LICENSE satisfies the filter. LICENSE whose
+// rules are unknown always matches, user decides it by reading it.
+func (f ruleFilter) match(l *License) bool {
+	if !hasRules(l) {
+		return true
+	}
+
+	for _, p := range f.permissions {
+		if !contains(l.Permissions, p) {
+			return false
+		}
+	}
+
+	if len(f.conditions) > 0 {
+		found := false
+		for _, c := range f.conditions {
+			if contains(l.Conditions, c) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, c := range f.noConditions {
+		if contains(l.Conditions, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// answer is an answer of question and LICENSE it leads to.
+type answer struct {
+	text   string
+	filter ruleFilter
+}
+
+// question is a question of the chooser wizard. The last answer
+// is the default one and it should not filter anything.
+type question struct {
+	text    string
+	answers []answer
+}
+
+// dontMind is the answer which doesn't filter LICENSE.
+var dontMind = answer{text: "I don't mind."}
+
+// questions are questions of the chooser wizard like
+// http://choosealicense.com/. LICENSE is filtered by each answer.
+var questions = []question{
+	{
+		text: "Do you want to require others to share their changes under the same LICENSE?",
+		answers: []answer{
+			{
+				text:   "No. Anyone can do almost anything with it, even in closed source (permissive).",
+				filter: ruleFilter{noConditions: []string{"same-license", "same-license--file", "same-license--library"}},
+			},
+			{
+				text:   "Only changes to my files or my library must be shared (weak copyleft).",
+				filter: ruleFilter{conditions: []string{"same-license--file", "same-license--library"}},
+			},
+			{
+				text:   "Yes. Any derivative work must be released under the same LICENSE (copyleft).",
+				filter: ruleFilter{conditions: []string{"same-license"}},
+			},
+			dontMind,
+		},
+	},
+	{
+		text: "Do you want contributors to grant patent rights to users?",
+		answers: []answer{
+			{
+				text:   "Yes, I'm concerned about patents.",
+				filter: ruleFilter{permissions: []string{"patent-use"}},
+			},
+			dontMind,
+		},
+	},
+	{
+		text: "Should users who interact with it over a network (e.g., web service) receive its source?",
+		answers: []answer{
+			{
+				text:   "Yes. Running it as a service is distribution.",
+				filter: ruleFilter{conditions: []string{"network-use-disclose"}},
+			},
+			{
+				text:   "No. Only distributing copies requires disclosing source.",
+				filter: ruleFilter{noConditions: []string{"network-use-disclose"}},
+			},
+			dontMind,
+		},
+	},
+	{
+		text: "Can software under other LICENSE (including proprietary) use it as a library?",
+		answers: []answer{
+			{
+				text:   "Yes. Linking to it must not force the LICENSE on the other software.",
+				filter: ruleFilter{noConditions: []string{"same-license"}},
+			},
+			dontMind,
+		},
+	},
+	{
+		text: "Do you require the copyright and LICENSE notice to be kept in copies?",
+		answers: []answer{
+			{
+				text:   "Yes, I want attribution.",
+				filter: ruleFilter{conditions: []string{"include-copyright", "include-copyright--source"}},
+			},
+			{
+				text:   "No. I want to dedicate it to the public domain.",
+				filter: ruleFilter{noConditions: []string{"include-copyright", "include-copyright--source"}},
+			},
+			dontMind,
+		},
+	},
+}
+
+// filterLicenses returns LICENSE which satisfies filter.
+func filterLicenses(list []*License, filter ruleFilter) []*License {
+	var matched []*License
+	for _, l := range list {
+		if filter.match(l) {
+			matched = append(matched, l)
+		}
+	}
+	return matched
+}
+
+// hasRules returns true if LICENSE has metadata of rules.
+func hasRules(l *License) bool {
+	return len(l.Permissions)+len(l.Conditions)+len(l.Limitations) > 0
+}
+
+// chooseCandidates returns all LICENSE in source with metadata of rules.
+// When the source doesn't provide it in the list (e.g., GitHub API),
+// metadata is taken from bundled one or fetched from the source. LICENSE
+// without metadata is still a candidate and its rules are unknown.
+func (cli *CLI) chooseCandidates() ([]*License, error) {
+	list, err := cli.source.List()
+	if err != nil {
+		return nil, err
+	}
+
+	bundled := newBundledSource()
+	candidates := make([]*License, 0, len(list))
+	for _, l := range list {
+		if !hasRules(l) {
+			if b, err := bundled.Get(l.Key); err == nil && hasRules(b) {
+				l = b
+			} else if f, err := cli.source.Get(l.Key); err == nil && hasRules(f) {
+				l = f
+			} else {
+				Debugf("Rules of %s are unknown", l.Key)
+			}
+		}
+		candidates = append(candidates, l)
+	}
+
+	// Featured LICENSE first
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Featured != candidates[j].Featured {
+			return candidates[i].Featured
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	return candidates, nil
+}
+
+// Choose asks questions like http://choosealicense.com/ and filters all
+// LICENSE in source by their permissions, conditions and limitations.
+// Then it recommends matching LICENSE and asks user to choose one of them.
+// It returns key to fetch LICENSE file. If something is wrong, return error.
+func (cli *CLI) Choose() (string, error) {
+	candidates, err := cli.chooseCandidates()
+	if err != nil {
+		return "", err
+	}
+
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no LICENSE to choose")
+	}
+
+	colorstring.Fprintf(cli.errStream, chooseText)
+
+	for _, q := range questions {
+		// Show only answers which leave some LICENSE and skip
+		// the question when answers can't narrow LICENSE down
+		var answers []answer
+		var results [][]*License
+		narrow := false
+		for _, a := range q.answers {
+			matched := filterLicenses(candidates, a.filter)
+			if len(matched) == 0 {
+				continue
+			}
+			if len(matched) < len(candidates) {
+				narrow = true
+			}
+			answers = append(answers, a)
+			results = append(results, matched)
+		}
+
+		if !narrow {
+			Debugf("Skip question: %s", q.text)
+			continue
+		}
+
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "\n%s\n", q.text)
+		for i, a := range answers {
+			fmt.Fprintf(&buf, "  %d) %s\n", i+1, a.text)
+		}
+		fmt.Fprint(cli.errStream, buf.String())
+
+		num, err := cli.AskNumber(len(answers), len(answers))
+		if err != nil {
+			return "", err
+		}
+		candidates = results[num-1]
+	}
+
+	return cli.recommend(candidates)
+}
+
+// recommend shows LICENSE with its description and asks user
+// to choose one of them.
+func (cli *CLI) recommend(candidates []*License) (string, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\nThe following LICENSE match your answers:\n")
+
+	// Use MIT as default if it's recommended
+	defaultNum := 1
+	for i, l := range candidates {
+		if l.Key == "mit" {
+			defaultNum = i + 1
+		}
+
+		fmt.Fprintf(&buf, "\n  %d) %s (%s)\n", i+1, l.Name, l.Key)
+		if l.Description != "" {
+			buf.WriteString(wrapText(l.Description, 80, "     "))
+		}
+
+		var conditions []string
+		for _, c := range l.Conditions {
+			conditions = append(conditions, ruleLabel(c))
+		}
+		switch {
+		case !hasRules(l):
+			conditions = []string{"unknown"}
+		case len(conditions) == 0:
+			conditions = []string{"none"}
+		}
+		buf.WriteString(wrapText("Conditions: "+strings.Join(conditions, ", "), 80, "     "))
+	}
+	buf.WriteString("\n")
+	fmt.Fprint(cli.errStream, buf.String())
+
+	num, err := cli.AskNumber(len(candidates), defaultNum)
+	if err != nil {
+		return "", err
+	}
+
+	return candidates[num-1].Key, nil
+}
+
+var chooseText = `Choose LICENSE like http://choosealicense.com/
+
+  [blue]Choosing an OSS license doesn't need to be scary[reset]
+
+Answer the following questions. LICENSE which match your answers
+are recommended at the end.
+`
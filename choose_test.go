@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFilterLicenses(t *testing.T) {
+	cli := &CLI{source: newBundledSource()}
+	candidates, err := cli.chooseCandidates()
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	cases := []struct {
+		answers  []answer
+		expected []string
+	}{
+		{
+			// Permissive and patents
+			answers:  []answer{questions[0].answers[0], questions[1].answers[0]},
+			expected: []string{"apache-2.0"},
+		},
+		{
+			// Network use
+			answers:  []answer{questions[2].answers[0]},
+			expected: []string{"agpl-3.0"},
+		},
+		{
+			// Public domain
+			answers:  []answer{questions[4].answers[1]},
+			expected: []string{"cc0-1.0", "unlicense"},
+		},
+	}
+
+	for i, tc := range cases {
+		list := candidates
+		for _, a := range tc.answers {
+			list = filterLicenses(list, a.filter)
+		}
+
+		var keys []string
+		for _, l := range list {
+			keys = append(keys, l.Key)
+		}
+		if len(keys) != len(tc.expected) {
+			t.Errorf("#%d expected %v to eq %v", i, keys, tc.expected)
+			continue
+		}
+		for j := range keys {
+			if keys[j] != tc.expected[j] {
+				t.Errorf("#%d expected %v to eq %v", i, keys, tc.expected)
+			}
+		}
+	}
+}
+
+// listOnlySource is LicenseSource which provides rules only by Get
+// like GitHub API.
+type listOnlySource struct {
+	fakeSource
+}
+
+func (s listOnlySource) List() ([]*License, error) {
+	var list []*License
+	for _, l := range s.fakeSource {
+		list = append(list, &License{Key: l.Key, Name: l.Name})
+	}
+	return list, nil
+}
+
+func TestChooseCandidates_notBundled(t *testing.T) {
+	source := listOnlySource{fakeSource{
+		"company": {
+			Key:        "company",
+			Name:       "Company License",
+			Conditions: []string{"include-copyright"},
+		},
+		"unknown": {
+			Key:  "unknown",
+			Name: "Unknown License",
+		},
+	}}
+
+	cli := &CLI{source: source}
+	candidates, err := cli.chooseCandidates()
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	if len(candidates) != 2 {
+		t.Fatalf("expected %d candidates, got %d", 2, len(candidates))
+	}
+	if !hasRules(candidates[0]) || candidates[0].Key != "company" {
+		t.Errorf("expected rules of company to be fetched: %#v", candidates[0])
+	}
+
+	// LICENSE whose rules are unknown is never filtered out
+	list := filterLicenses(candidates, questions[4].answers[1].filter)
+	if len(list) != 1 || list[0].Key != "unknown" {
+		t.Errorf("expected only unknown to match, got %#v", list)
+	}
+}
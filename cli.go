@@ -2,22 +2,18 @@ package main
 
 import (
 	"bytes"
-	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"net/http"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/google/go-github/github"
-	"github.com/mitchellh/go-homedir"
 	"github.com/olekukonko/tablewriter"
-	"github.com/tcnksm/go-gitconfig"
 )
 
 // Exit codes are int values that represent an exit code for a particular error.
@@ -25,6 +21,7 @@ const (
 	ExitCodeOK    int = 0
 	ExitCodeError int = 1 + iota
 	ExitCodeErrorCache
+	ExitCodeCheckFailed
 )
 
 const (
@@ -36,53 +33,91 @@ const (
 
 	// Default value for user input
 	DoNothing = "(no replacement)"
+
+	// DefaultTemplatesDir is directory (relative to home) for custom
+	// LICENSE templates. Each template is named KEY.txt.
+	DefaultTemplatesDir = ".config/license/templates"
+
+	// ExceptionsDirName is directory (in templates directory) for
+	// custom LICENSE exceptions, e.g., used with WITH in SPDX expression.
+	ExceptionsDirName = "exceptions"
 )
 
+// subcommands are commands run by `license COMMAND [option] [args]`.
+// The first argument is the command name.
+var subcommands = map[string]func(cli *CLI, args []string) int{
+	"header":        (*CLI).runHeader,
+	"check-headers": (*CLI).runCheckHeaders,
+	"detect":        (*CLI).runDetect,
+	"deps":          (*CLI).runDeps,
+	"compat":        (*CLI).runCompat,
+	"policy":        (*CLI).runPolicy,
+	"show":          (*CLI).runShow,
+	"compare":       (*CLI).runCompare,
+	"diff":          (*CLI).runDiff,
+	"update-year":   (*CLI).runUpdateYear,
+}
+
 // CLI is the command line object
 type CLI struct {
 	// outStream and errStream are the stdout and stderr
 	// to write message from the CLI.
 	outStream, errStream io.Writer
+
+	// source is where LICENSE is fetched from. If it's nil, setup
+	// builds the chain of custom templates, SPDX data (if provided),
+	// GitHub API (unless offline) and the bundled snapshot.
+	source LicenseSource
+
+	// exceptions is where LICENSE exceptions (e.g., Classpath
+	// exception) are fetched from. If it's nil, bundled ones are used.
+	exceptions LicenseSource
+
+	// nonInteractive is true when CLI never asks user.
+	// Default values are used instead.
+	nonInteractive bool
 }
 
 // Run invokes the CLI with the given arguments.
 func (cli *CLI) Run(args []string) int {
 
-	var (
-		output string
-
-		optionYear    string
-		optionAuthor  string
-		optionEmail   string
-		optionProject string
+	// Run subcommand if it's provided
+	if len(args) > 1 {
+		if cmd, ok := subcommands[args[1]]; ok {
+			return cmd(cli, args[1:])
+		}
+	}
 
-		force   bool
-		noCache bool
-		raw     bool
+	var (
+		output      string
+		authorsFile string
+		force       bool
+		raw         bool
+		notice      bool
+		manifest    bool
+		o           options
 	)
 
 	// Define option flag parse
 	flags := flag.NewFlagSet(Name, flag.ContinueOnError)
 	flags.SetOutput(cli.errStream)
 	flags.Usage = func() {
-		fmt.Fprintf(cli.errStream, helpText)
+		fmt.Fprint(cli.errStream, helpText)
 	}
 
 	flags.StringVar(&output, "output", DefaultOutput, "")
-	flags.BoolVar(&noCache, "no-cache", false, "")
+	flags.StringVar(&authorsFile, "authors-file", "", "")
 	flags.BoolVar(&force, "force", false, "")
 	flags.BoolVar(&raw, "raw", false, "")
+	flags.BoolVar(&notice, "notice", false, "")
+	flags.BoolVar(&manifest, "manifest", false, "")
 
-	// Replacement values
-	flags.StringVar(&optionYear, "year", DefaultValue, "")
-	flags.StringVar(&optionAuthor, "author", DefaultValue, "")
-	flags.StringVar(&optionEmail, "email", DefaultValue, "")
-	flags.StringVar(&optionProject, "project", DefaultValue, "")
+	// Replacement values and options shared with subcommands
+	o.register(flags)
 
 	flList := flags.Bool("list", false, "")
 	flChoose := flags.Bool("choose", false, "")
 
-	flDebug := flags.Bool("debug", false, "")
 	flVersion := flags.Bool("version", false, "")
 
 	// This is only for dev (and test)
@@ -110,37 +145,31 @@ func (cli *CLI) Run(args []string) int {
 		return ExitCodeOK
 	}
 
-	// Set Debug environmental variable
-	if *flDebug {
-		os.Setenv(EnvDebug, "1")
-		Debugf("Run as DEBUG mode")
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	if !isFlagSet(flags, "output") && o.config.Output != "" {
+		output = o.config.Output
 	}
 
 	// Show list of LICENSE and quit
 	if *flList || *flListkeys {
 		Debugf("Show list of LICENSE")
 
-		// Create default client
-		client := github.NewClient(nil)
-
-		// Fetch list from Github API
-		list, res, err := client.Licenses.List(context.Background())
+		list, err := cli.source.List()
 		if err != nil {
 			fmt.Fprintf(cli.errStream, "Failed to fetch LICENSE list: %s\n", err.Error())
 			return ExitCodeError
 		}
 
-		if res.StatusCode != http.StatusOK {
-			fmt.Fprintf(cli.errStream, "Invalid status code from GitHub\n %s\n", res.String())
-			return ExitCodeError
-		}
-
 		// List LICENSE keys (name used when fetching)
 		// This is only for dev(testing)
 		if *flListkeys {
 			Debugf("List LICENSE keys")
 			for _, l := range list {
-				fmt.Fprintf(cli.outStream, "%s\n", *l.Key)
+				fmt.Fprintf(cli.outStream, "%s\n", l.Key)
 			}
 			return ExitCodeOK
 		}
@@ -152,13 +181,25 @@ func (cli *CLI) Run(args []string) int {
 		header := []string{"Key", "Name"}
 		table.SetHeader(header)
 		for _, l := range list {
-			Debugf("%s (%s)", *l.Name, *l.Key)
-			table.Append([]string{*l.Key, *l.Name})
+			Debugf("%s (%s)", l.Name, l.Key)
+			table.Append([]string{l.Key, l.Name})
 		}
 		table.Render()
 
 		outBuffer.WriteString("See more about these LICENSE at http://choosealicense.com/licenses/\n")
-		fmt.Fprintf(cli.outStream, outBuffer.String())
+
+		// LICENSE exceptions are used with LICENSE by WITH
+		if exceptions := cli.loadExceptions(); len(exceptions) > 0 {
+			outBuffer.WriteString("\nExceptions (use with LICENSE, e.g., 'gpl-2.0 WITH classpath-exception-2.0'):\n")
+			table := tablewriter.NewWriter(outBuffer)
+			table.SetHeader(header)
+			for _, e := range exceptions {
+				table.Append([]string{e.Key, e.Name})
+			}
+			table.Render()
+		}
+
+		fmt.Fprint(cli.outStream, outBuffer.String())
 
 		return ExitCodeOK
 	}
@@ -169,18 +210,32 @@ func (cli *CLI) Run(args []string) int {
 		return ExitCodeError
 	}
 
-	parsedArgs := flags.Args()
-	if len(parsedArgs) > 1 {
-		fmt.Fprintf(cli.errStream, "Invalid arguments\n")
-		return ExitCodeError
+	// AUTHORS file is generated alongside LICENSE
+	if authorsFile != "" {
+		authorsFile = filepath.Join(filepath.Dir(output), authorsFile)
+		if _, err := os.Stat(authorsFile); !os.IsNotExist(err) && !force {
+			fmt.Fprintf(cli.errStream, "Cannot create file %q: file exists\n", authorsFile)
+			return ExitCodeError
+		}
+
+		if len(o.holders) == 0 {
+			fmt.Fprintf(cli.errStream, "Copyright holders are required to create %q: use -author option or holders in config file\n", authorsFile)
+			return ExitCodeError
+		}
 	}
 
+	// SPDX expression may be provided without quotes,
+	// e.g., license mit OR apache-2.0
+	parsedArgs := flags.Args()
+
 	var key string
-	if len(parsedArgs) == 1 {
-		key = parsedArgs[0]
-		// Every key must be lower case
-		key = strings.ToLower(key)
+	if len(parsedArgs) > 0 {
+		key = strings.Join(parsedArgs, " ")
+	} else if !*flChoose {
+		key = o.config.License
 	}
+	// Every key must be lower case
+	key = strings.ToLower(key)
 
 	// Choose a LICENSE like http://choosealicense.com/
 	if len(key) == 0 && *flChoose {
@@ -197,26 +252,30 @@ func (cli *CLI) Run(args []string) int {
 	if len(key) == 0 {
 		Debugf("Show all LICENSE available and ask user to select")
 
-		list, err := fetchLicenseList()
+		list, err := cli.source.List()
 		if err != nil {
 			fmt.Fprintf(cli.errStream, "Failed to show LICENSE list: %s", err.Error())
 			return ExitCodeError
 		}
 
 		sort.Slice(list, func(i, j int) bool {
-			return *list[i].Name < *list[j].Name
+			return list[i].Name < list[j].Name
 		})
 
 		var buf bytes.Buffer
 		buf.WriteString("Which of the following do you want to use?\n")
 		for i, l := range list {
-			fmt.Fprintf(&buf, "  %2d) %s\n", i+1, *l.Name)
+			fmt.Fprintf(&buf, "  %2d) %s\n", i+1, l.Name)
 		}
-		fmt.Fprintf(cli.errStream, buf.String())
+		fmt.Fprint(cli.errStream, buf.String())
 
-		// Use MIT as default, it may change in future
-		// So should fix it
-		defaultNum := 13
+		// Use MIT as default
+		defaultNum := 1
+		for i, l := range list {
+			if l.Key == "mit" {
+				defaultNum = i + 1
+			}
+		}
 
 		num, err := cli.AskNumber(len(list), defaultNum)
 		if err != nil {
@@ -224,128 +283,248 @@ func (cli *CLI) Run(args []string) int {
 			return ExitCodeError
 		}
 
-		key = *(list[num-1]).Key
+		key = list[num-1].Key
 	}
 
-	home, err := homedir.Dir()
+	expr, err := parseExpression(key)
 	if err != nil {
-		Debugf("Failed to get home directory: %s", err.Error())
-		noCache = true
-		home = "."
+		fmt.Fprintf(cli.errStream, "Invalid LICENSE expression %q: %s\n", key, err.Error())
+		return ExitCodeError
 	}
-	cacheDir := filepath.Join(home, CacheDirName)
 
-	// By default noCache is false (useCache) and check cache is exist or not
-	var body string
-	if !noCache {
-		var err error
-		body, err = getCache(key, cacheDir)
-		if err != nil {
-			Debugf("Failed to get cache: %s", err.Error())
+	// Multiple LICENSE (e.g., "mit OR apache-2.0") are written in
+	// LICENSE-MIT and LICENSE-APACHE, and LICENSE explains them
+	_, compound := expr.(*binaryExpr)
+	refs := expr.licenses()
+	names := expressionFileNames(refs)
+	outputs := []string{output}
+	if compound {
+		outputs = nil
+		for _, name := range names {
+			path := filepath.Join(filepath.Dir(output), name)
+			if _, err := os.Stat(path); !os.IsNotExist(err) && !force {
+				fmt.Fprintf(cli.errStream, "Cannot create file %q: file exists\n", path)
+				return ExitCodeError
+			}
+			outputs = append(outputs, path)
 		}
 	}
 
-	// If cache is not exist, fetch it from GitHub
-	var fetched bool = false
-	if len(body) == 0 {
-		var err error
-		body, err = fetchLicense(key)
+	// NOTICE file is required by Apache License 2.0
+	noticePath := filepath.Join(filepath.Dir(output), NoticeFileName)
+	if notice {
+		apache := false
+		for _, ref := range refs {
+			if ref.key == "apache-2.0" {
+				apache = true
+			}
+		}
+		if !apache {
+			fmt.Fprintf(cli.errStream, "Cannot create NOTICE file: it's only for apache-2.0\n")
+			return ExitCodeError
+		}
+
+		if _, err := os.Stat(noticePath); !os.IsNotExist(err) && !force {
+			fmt.Fprintf(cli.errStream, "Cannot create file %q: file exists\n", noticePath)
+			return ExitCodeError
+		}
+	}
+
+	var (
+		licenses   []*License
+		exceptions []*License
+		bodies     []string
+		cached     bool
+	)
+	for _, ref := range refs {
+		license, c, err := cli.getLicense(ref.key, &o)
 		if err != nil {
 			fmt.Fprintf(cli.errStream, "Failed to get LICENSE file: %s\n", err.Error())
 			return ExitCodeError
 		}
+		body := license.Body
+		cached = cached || c
 
-		if !noCache {
-			err := setCache(body, key, cacheDir)
+		// Exception is appended to LICENSE, e.g., "gpl-2.0 WITH classpath-exception-2.0"
+		var exception *License
+		if ref.exception != "" {
+			exception, err = cli.exceptions.Get(ref.exception)
 			if err != nil {
-				Debugf("Failed to save cache: %s", err.Error())
+				fmt.Fprintf(cli.errStream, "Failed to get LICENSE exception: %s\n", err.Error())
+				return ExitCodeError
 			}
+			body = appendException(body, exception)
 		}
 
-		fetched = true
-	}
+		// Replace place holders
+		if !raw {
 
-	// Create output path if it is not exist
-	dir, _ := filepath.Split(output)
-	if len(dir) != 0 {
-		os.MkdirAll(dir, 0777)
+			var unresolved []string
+			body, unresolved = cli.ReplacePlaceholders(body, ref.key, o.placeholderOptions())
+
+			// In non-interactive mode, nobody can answer the value.
+			// Stop generating instead of leaving placeholders.
+			if len(unresolved) > 0 {
+				cli.printUnresolved(unresolved)
+				return ExitCodeError
+			}
+		}
+
+		licenses = append(licenses, license)
+		exceptions = append(exceptions, exception)
+		bodies = append(bodies, body)
 	}
 
-	licenseWriter, err := os.Create(output)
-	if err != nil {
-		fmt.Fprintf(cli.errStream, "Failed to create file %s: %s\n", output, err.Error())
-		return ExitCodeError
+	if compound {
+		outputs = append(outputs, output)
+		bodies = append(bodies, expressionText(expr, licenses, exceptions, names))
 	}
-	defer licenseWriter.Close()
-	Debugf("Output filename: %s", output)
-
-	// Replace place holders
-	if !raw {
-
-		// Replace year if needed
-		var year string
-		if optionYear != DefaultValue {
-			year = optionYear
-		} else {
-			year = strconv.Itoa(time.Now().Year())
+
+	if notice {
+		body, unresolved := cli.ReplacePlaceholders(noticeTemplate, "apache-2.0", o.placeholderOptions())
+		if len(unresolved) > 0 {
+			cli.printUnresolved(unresolved)
+			return ExitCodeError
 		}
 
-		yearFolders := findPlaceholders(body, yearKeys)
-		for _, f := range yearFolders {
-			fmt.Fprintf(cli.errStream, "----> Replace placeholder %q to %q in LICENSE body\n", f, year)
-			body = strings.Replace(body, f, year, -1)
+		// NOTICE of vendored packages must be redistributed
+		vendored, err := findVendoredNotices(filepath.Dir(output))
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to read NOTICE of vendored packages: %s\n", err.Error())
+			return ExitCodeError
 		}
 
-		// Replace author name if needed
-		defaultAuthor, _ := gitconfig.GithubUser()
-		if len(defaultAuthor) == 0 {
-			defaultAuthor = DoNothing
+		outputs = append(outputs, noticePath)
+		bodies = append(bodies, noticeText(body, vendored))
+	}
+
+	// SPDX ID is required for license field of package manifests
+	var spdxID string
+	if manifest {
+		var err error
+		spdxID, err = spdxExpression(expr, licenses, exceptions)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Cannot update package manifests: %s\n", err.Error())
+			return ExitCodeError
 		}
-		body = cli.ReplacePlaceholder(body, nameKeys, "Input author name", defaultAuthor, optionAuthor)
+	}
 
-		// Replace email if needed
-		defaultEmail, _ := gitconfig.Email()
-		if len(defaultEmail) == 0 {
-			defaultEmail = DoNothing
+	for i, path := range outputs {
+		if err := writeFile(path, bodies[i]); err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to write license body to %q: %s\n", path, err.Error())
+			return ExitCodeError
 		}
-		body = cli.ReplacePlaceholder(body, nameKeys, "Input email", defaultEmail, optionEmail)
+		Debugf("Output filename: %s", path)
+	}
 
-		// Replace project name if needed
-		body = cli.ReplacePlaceholder(body, projectKeys, "Input project name", DoNothing, optionProject)
+	if authorsFile != "" {
+		if err := writeFile(authorsFile, authorsText(o.project, o.holders)); err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to write authors to %q: %s\n", authorsFile, err.Error())
+			return ExitCodeError
+		}
+		Debugf("Authors filename: %s", authorsFile)
 	}
 
-	// Write LICENSE body to file
-	_, err = io.Copy(licenseWriter, strings.NewReader(body))
-	if err != nil {
-		fmt.Fprintf(cli.errStream, "Failed to write license body to %q: %s\n", output, err.Error())
-		return ExitCodeError
+	// Update license field of package manifests in working directory
+	if manifest {
+		paths, err := findManifests(".")
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to find package manifests: %s\n", err.Error())
+			return ExitCodeError
+		}
+
+		if len(paths) == 0 {
+			fmt.Fprintf(cli.errStream, "Package manifest is not found in working directory\n")
+		}
+
+		failed := false
+		for _, path := range paths {
+			err := updateManifest(path, spdxID)
+			if errors.Is(err, errNotManifest) {
+				fmt.Fprintf(cli.errStream, "Warning: skip %s: %s\n", path, err.Error())
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(cli.errStream, "Failed to update license field of %s: %s\n", path, err.Error())
+				failed = true
+				continue
+			}
+			fmt.Fprintf(cli.errStream, "----> Update license field of %s to %q\n", path, spdxID)
+		}
+
+		if failed {
+			return ExitCodeError
+		}
 	}
 
 	// Output message to user
 	var msg bytes.Buffer
 	msg.WriteString(fmt.Sprintf("====> Successfully generated %q LICENSE", key))
-	if !noCache && !fetched {
+	if cached {
 		msg.WriteString(" (Use cache)")
 	}
 
-	fmt.Fprintf(cli.errStream, msg.String()+"\n")
+	fmt.Fprintln(cli.errStream, msg.String())
 
 	return ExitCodeOK
 }
 
+// writeFile writes body to path. Its directory is created if it's
+// not exist.
+func writeFile(path, body string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		os.MkdirAll(dir, 0777)
+	}
+	return ioutil.WriteFile(path, []byte(body), 0666)
+}
+
 var helpText = `Usage: license [option] [KEY]
+       license COMMAND [option] [args]
 
   Generate LICENSE file. If you provide KEY, it will try to get LICENSE by
   it. If you don't provide it, it will ask you to choose from avairable list.
   You can check avairable LICESE list by '-list' option.
 
+  KEY can be SPDX license expression (e.g., 'mit OR apache-2.0') to
+  generate multiple LICENSE. Each LICENSE is written in its own file
+  (e.g., LICENSE-MIT and LICENSE-APACHE) and LICENSE explains them.
+  Text of LICENSE exception is appended by WITH (e.g., 'gpl-2.0 WITH
+  classpath-exception-2.0'). Exceptions are shown by '-list' option.
+
+Commands:
+
+  header              Insert license header to source files.
+
+  check-headers       Check license header of source files on CI.
+
+  detect              Detect LICENSE of existing projects.
+
+  deps                Show LICENSE of Go modules the project depends on.
+
+  compat              Check LICENSE compatibility of dependencies.
+
+  policy check        Check LICENSE of the project and vendored packages
+                      against allowed and denied LICENSE in policy file.
+
+  show                Show permissions, conditions and limitations of LICENSE.
+
+  compare             Compare permissions, conditions and limitations
+                      of LICENSE side by side.
+
+  diff                Show changes of LICENSE file from the canonical text.
+
+  update-year         Update copyright year in LICENSE file and headers.
+
+  Run 'license COMMAND -help' to see usage of each command.
+
 Options:
 
   -list               Show all avairable LICENSE list and quit.
                       It will fetch information from GitHub.
 
   -choose             Choose LICENSE like http://choosealicense.com/
-                      It shows you which LICENSE is useful for you.
+                      It asks some questions and recommends LICENSE
+                      which match your answers.
 
   -output=NAME        Change output file name.
                       By default, output file name is 'LICENSE'
@@ -361,4 +540,81 @@ Options:
   -raw                Generate raw LICENSE file.
                       By default, it replace year, name, or email
 
+  -year=YEAR          Replace year placeholder with YEAR.
+                      By default, current year is used. If YEAR is
+                      'git', years of the first and the last commit
+                      in local git history are used (e.g., 2015-2020).
+
+  -author=NAME        Replace copyright holder placeholder with NAME.
+                      It can be repeated for multiple copyright holders,
+                      then the copyright line is repeated for each of
+                      them. If NAME is 'git', contributors in local git
+                      history are used.
+
+  -authors-file=NAME  Also generate file NAME (e.g., AUTHORS) which lists
+                      copyright holders alongside LICENSE.
+
+  -manifest           Update license field of package manifests in working
+                      directory (package.json, composer.json, Cargo.toml,
+                      pyproject.toml, setup.cfg, *.gemspec) to SPDX ID.
+
+  -notice             Also generate NOTICE file for apache-2.0 with
+                      project name and copyright line. NOTICE files in
+                      vendored packages (vendor, third_party) are merged.
+
+  -email=EMAIL        Replace email placeholder with EMAIL.
+
+  -project=NAME       Replace project name placeholder with NAME.
+
+  -description=TEXT   Replace one line description of the project
+                      (e.g., in "How to apply" section of GPL).
+                      By default, project name is used.
+
+  -templates=DIR      Read custom LICENSE templates (KEY.txt) from DIR.
+                      They are shown in the list and can be generated
+                      like other LICENSE. By default, templates are
+                      read from ~/.config/license/templates.
+                      LICENSE exceptions are read from DIR/exceptions.
+
+  -spdx=PATH|URL      Use SPDX License List data (JSON) as LICENSE source.
+                      PATH or URL must contain licenses.json and
+                      details/ID.json, e.g., json directory of
+                      https://github.com/spdx/license-list-data
+                      KEY is SPDX ID in lower case (e.g., 'isc').
+
+  -token=TOKEN        GitHub API token to raise the rate limit.
+                      By default, GITHUB_TOKEN environmental variable
+                      is used.
+
+  -github-api=URL     GitHub API base URL, e.g., GitHub Enterprise
+                      (https://github.example.com/api/v3/).
+
+  -yes, -non-interactive
+                      Never ask anything. Default values are used and
+                      it fails when placeholders can't be replaced.
+                      It's enabled when stdin is not terminal.
+
+  -offline            Never access the network. LICENSE is taken from
+                      local cache or the snapshot bundled in the binary.
+                      By default, the bundled one is used only when
+                      GitHub API is not available.
+
+Config:
+
+  Default values of options can be written in ~/.config/license/config.toml
+  (user-level) or .licenserc (repository-level, searched from current
+  directory to the repository root). Both are TOML. Flags take precedence
+  over the repository-level one, then the user-level one, then gitconfig.
+
+    author         = "Taichi Nakashima"
+    email          = "nsd22843@gmail.com"
+    project        = "license"
+    description    = "Generate LICENSE file"
+    license        = "mit"
+    output         = "LICENSE"
+    templates      = "~/.config/license/templates"
+    no-cache       = false
+    cache-dir      = "~/.lcns"
+    cache-duration = "720h"
+
 `
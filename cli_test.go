@@ -3,11 +3,69 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
 )
 
+// fakeSource is LicenseSource for testing. It doesn't access network.
+type fakeSource map[string]*License
+
+func (s fakeSource) List() ([]*License, error) {
+	list := make([]*License, 0, len(s))
+	for _, l := range s {
+		list = append(list, l)
+	}
+	return list, nil
+}
+
+func (s fakeSource) Get(key string) (*License, error) {
+	l, ok := s[key]
+	if !ok {
+		return nil, fmt.Errorf("license %q is not found", key)
+	}
+	return l, nil
+}
+
+var testSource = fakeSource{
+	"mit": {
+		Key:  "mit",
+		Name: "MIT License",
+		Body: "Copyright (c) [year] [fullname]\n",
+	},
+}
+
+// isolateRun makes the test run in a temporary directory with empty
+// HOME, so that user config and .licenserc of the working directory
+// where the test is run don't affect CLI.Run.
+func isolateRun(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+	homedir.Reset()
+	t.Cleanup(homedir.Reset)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
 func TestRun_versionFlag(t *testing.T) {
+	isolateRun(t)
+
 	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
 	cli := &CLI{outStream: outStream, errStream: errStream}
 	args := strings.Split("./license -version", " ")
@@ -24,23 +82,410 @@ func TestRun_versionFlag(t *testing.T) {
 }
 
 func TestRun_listFlag(t *testing.T) {
+	isolateRun(t)
+
 	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
-	cli := &CLI{outStream: outStream, errStream: errStream}
+	cli := &CLI{outStream: outStream, errStream: errStream, source: testSource}
 	args := strings.Split("./license -list", " ")
 
 	status := cli.Run(args)
 	if status != ExitCodeOK {
 		t.Errorf("expected %d to eq %d", status, ExitCodeOK)
 	}
+
+	expected := "MIT License"
+	if !strings.Contains(outStream.String(), expected) {
+		t.Errorf("expected %q to contain %q", outStream.String(), expected)
+	}
+}
+
+func TestRun_key(t *testing.T) {
+	isolateRun(t)
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream, source: testSource}
+
+	output := filepath.Join(t.TempDir(), "LICENSE")
+	args := []string{"./license", "-no-cache", "-output=" + output, "-year=2015", "-author=tcnksm", "mit"}
+
+	status := cli.Run(args)
+	if status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	b, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Copyright (c) 2015 tcnksm\n"
+	if string(b) != expected {
+		t.Errorf("expected %q to eq %q", string(b), expected)
+	}
 }
 
 func TestRun_chooseFlag(t *testing.T) {
+	isolateRun(t)
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream, source: testSource}
+
+	output := filepath.Join(t.TempDir(), "LICENSE")
+	args := []string{"./license", "-no-cache", "-raw", "-output=" + output, "-choose"}
+
+	status := cli.Run(args)
+	if status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	// MIT is recommended by default
+	expected := "MIT License (mit)"
+	if !strings.Contains(errStream.String(), expected) {
+		t.Errorf("expected %q to contain %q", errStream.String(), expected)
+	}
+
+	b, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != testSource["mit"].Body {
+		t.Errorf("expected %q to eq %q", string(b), testSource["mit"].Body)
+	}
+}
+
+func TestRun_offlineFlag(t *testing.T) {
+	isolateRun(t)
+
 	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
 	cli := &CLI{outStream: outStream, errStream: errStream}
-	args := strings.Split("./license -choose", " ")
+
+	output := filepath.Join(t.TempDir(), "LICENSE")
+	args := []string{"./license", "-offline", "-no-cache", "-raw", "-output=" + output, "apache-2.0"}
 
 	status := cli.Run(args)
 	if status != ExitCodeOK {
-		t.Errorf("expected %d to eq %d", status, ExitCodeOK)
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	b, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Apache License"
+	if !strings.Contains(string(b), expected) {
+		t.Errorf("expected %q to contain %q", string(b), expected)
+	}
+}
+
+func TestRun_templatesFlag(t *testing.T) {
+	isolateRun(t)
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream}
+
+	dir := t.TempDir()
+	template := "---\ntitle: Company Proprietary License\n---\n\nCopyright (c) [year] [fullname]\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "company.txt"), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"./license", "-offline", "-templates=" + dir, "-list"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	expected := "Company Proprietary License"
+	if !strings.Contains(outStream.String(), expected) {
+		t.Errorf("expected %q to contain %q", outStream.String(), expected)
+	}
+
+	output := filepath.Join(dir, "LICENSE")
+	args = []string{"./license", "-offline", "-no-cache", "-templates=" + dir, "-output=" + output, "-year=2015", "-author=tcnksm", "company"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	b, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "Copyright (c) 2015 tcnksm\n"
+	if string(b) != expected {
+		t.Errorf("expected %q to eq %q", string(b), expected)
+	}
+}
+
+func TestRun_nonInteractive(t *testing.T) {
+	isolateRun(t)
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	source := fakeSource{
+		"project": {
+			Key:  "project",
+			Name: "Project License",
+			Body: "[project] is licensed under [year]\n",
+		},
+	}
+	cli := &CLI{outStream: outStream, errStream: errStream, source: source}
+
+	output := filepath.Join(t.TempDir(), "LICENSE")
+	args := []string{"./license", "-yes", "-no-cache", "-output=" + output, "project"}
+
+	status := cli.Run(args)
+	if status != ExitCodeError {
+		t.Fatalf("expected %d to eq %d", status, ExitCodeError)
+	}
+
+	expected := "Failed to resolve placeholders in non-interactive mode: [project]"
+	if !strings.Contains(errStream.String(), expected) {
+		t.Errorf("expected %q to contain %q", errStream.String(), expected)
+	}
+
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("expected %q not to be created", output)
+	}
+}
+
+func TestRun_detect(t *testing.T) {
+	isolateRun(t)
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream}
+
+	dir := t.TempDir()
+	args := []string{"./license", "-offline", "-no-cache", "-yes", "-year=2015", "-author=tcnksm", "-output=" + filepath.Join(dir, "LICENSE"), "bsd-3-clause"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	outStream.Reset()
+	args = []string{"./license", "detect", "-offline", "-no-cache", "-json", dir}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	expected := `"key": "bsd-3-clause"`
+	if !strings.Contains(outStream.String(), expected) {
+		t.Errorf("expected %q to contain %q", outStream.String(), expected)
+	}
+}
+
+func TestRun_expression(t *testing.T) {
+	isolateRun(t)
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream}
+
+	dir := t.TempDir()
+	args := []string{"./license", "-offline", "-no-cache", "-yes", "-year=2015", "-author=tcnksm", "-output=" + filepath.Join(dir, "LICENSE"), "mit", "OR", "apache-2.0"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	for name, expected := range map[string]string{
+		"LICENSE-MIT":    "Copyright (c) 2015 tcnksm",
+		"LICENSE-APACHE": "Copyright 2015 tcnksm",
+		"LICENSE":        "MIT OR Apache-2.0",
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("expected %s to contain %q: %s", name, expected, string(b))
+		}
+	}
+}
+
+func TestRun_detectException(t *testing.T) {
+	isolateRun(t)
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream}
+
+	dir := t.TempDir()
+	args := []string{"./license", "-offline", "-no-cache", "-yes", "-year=2015", "-author=tcnksm", "-output=" + filepath.Join(dir, "LICENSE"), "apache-2.0", "WITH", "llvm-exception"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "LICENSE"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(string(b), "LLVM Exceptions to the Apache 2.0 License") {
+		t.Errorf("expected LICENSE to contain exception: %s", string(b))
+	}
+
+	outStream.Reset()
+	args = []string{"./license", "detect", "-offline", "-no-cache", "-json", dir}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	for _, expected := range []string{`"key": "apache-2.0"`, `"exception": "llvm-exception"`} {
+		if !strings.Contains(outStream.String(), expected) {
+			t.Errorf("expected %q to contain %q", outStream.String(), expected)
+		}
+	}
+}
+
+func TestRun_notice(t *testing.T) {
+	isolateRun(t)
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream}
+
+	dir := t.TempDir()
+	vendored := filepath.Join(dir, "vendor", "github.com", "foo", "bar")
+	if err := os.MkdirAll(vendored, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for name, content := range map[string]string{
+		"LICENSE": "Apache License\n",
+		"NOTICE":  "Bar\nCopyright 2010 Foo\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(vendored, name), []byte(content), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	args := []string{"./license", "-offline", "-no-cache", "-yes", "-year=2015", "-author=tcnksm", "-project=license", "-notice", "-output=" + filepath.Join(dir, "LICENSE"), "apache-2.0"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "NOTICE"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, expected := range []string{"license\nCopyright 2015 tcnksm\n", "github.com/foo/bar", "Copyright 2010 Foo"} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("expected %q to contain %q", string(b), expected)
+		}
+	}
+
+	args = []string{"./license", "-offline", "-no-cache", "-yes", "-notice", "-output=" + filepath.Join(dir, "LICENSE-MIT"), "mit"}
+	if status := cli.Run(args); status != ExitCodeError {
+		t.Errorf("expected %d to eq %d", status, ExitCodeError)
+	}
+}
+
+func TestRun_githubRateLimit(t *testing.T) {
+	isolateRun(t)
+
+	reset := time.Now().Add(time.Hour)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "API rate limit exceeded for 127.0.0.1."}`)
+	}))
+	defer ts.Close()
+
+	expected := reset.Local().Format(time.RFC1123)
+	api := "-github-api=" + ts.URL + "/api/v3/"
+
+	// Bundled LICENSE is used with warning
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream}
+	output := filepath.Join(t.TempDir(), "LICENSE")
+	args := []string{"./license", api, "-no-cache", "-yes", "-raw", "-output=" + output, "mit"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+	if !strings.Contains(errStream.String(), expected) {
+		t.Errorf("expected %q to contain %q", errStream.String(), expected)
+	}
+
+	// Rate limit error is preferred to not found error of other sources
+	errStream.Reset()
+	args = []string{"./license", api, "-no-cache", "-yes", "-raw", "-output=" + output, "-force", "wtfpl"}
+	if status := cli.Run(args); status != ExitCodeError {
+		t.Fatalf("expected %d to eq %d", status, ExitCodeError)
+	}
+	if strings.Contains(errStream.String(), "is not found") || !strings.Contains(errStream.String(), expected) {
+		t.Errorf("expected %q to contain %q", errStream.String(), expected)
+	}
+
+	errStream.Reset()
+	args = []string{"./license", api, "-list"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+	if !strings.Contains(errStream.String(), expected) {
+		t.Errorf("expected %q to contain %q", errStream.String(), expected)
+	}
+}
+
+func TestRun_offlineCache(t *testing.T) {
+	isolateRun(t)
+
+	// Cache saved by the previous run with GitHub API
+	home, err := homedir.Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := setCache("Cached MIT License\n", "mit", filepath.Join(home, CacheDirName)); err != nil {
+		t.Fatal(err)
+	}
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream}
+
+	output := filepath.Join(t.TempDir(), "LICENSE")
+	args := []string{"./license", "-offline", "-yes", "-raw", "-output=" + output, "mit"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	b, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Cached MIT License\n"
+	if string(b) != expected {
+		t.Errorf("expected %q to eq %q", string(b), expected)
+	}
+}
+
+func TestRun_manifest(t *testing.T) {
+	isolateRun(t)
+
+	// pyproject.toml which is only config of tools is skipped
+	for name, content := range map[string]string{
+		"package.json":   "{\n  \"name\": \"license\"\n}\n",
+		"pyproject.toml": "[tool.black]\nline-length = 88\n",
+	} {
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{outStream: outStream, errStream: errStream}
+
+	args := []string{"./license", "-offline", "-no-cache", "-yes", "-year=2015", "-author=tcnksm", "-manifest", "mit"}
+	if status := cli.Run(args); status != ExitCodeOK {
+		t.Fatalf("expected %d to eq %d: %s", status, ExitCodeOK, errStream.String())
+	}
+
+	b, err := ioutil.ReadFile("package.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"license": "MIT"`) {
+		t.Errorf("expected package.json to be updated: %s", string(b))
+	}
+
+	expected := "Warning: skip pyproject.toml"
+	if !strings.Contains(errStream.String(), expected) {
+		t.Errorf("expected %q to contain %q", errStream.String(), expected)
 	}
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// commentStyle is comment syntax of source file. Either line comment
+// (e.g., "//") or block comment (e.g., "/*", " *", " */") is used.
+type commentStyle struct {
+	line string
+
+	blockStart  string
+	blockMiddle string
+	blockEnd    string
+}
+
+var (
+	slashStyle  = commentStyle{line: "//"}
+	hashStyle   = commentStyle{line: "#"}
+	dashStyle   = commentStyle{line: "--"}
+	semiStyle   = commentStyle{line: ";;"}
+	cStyle      = commentStyle{blockStart: "/*", blockMiddle: " *", blockEnd: " */"}
+	markupStyle = commentStyle{blockStart: "<!--", blockMiddle: " ", blockEnd: "-->"}
+)
+
+// commentStyles are comment syntax by file extension.
+var commentStyles = map[string]commentStyle{
+	".cc":     slashStyle,
+	".cpp":    slashStyle,
+	".cs":     slashStyle,
+	".dart":   slashStyle,
+	".go":     slashStyle,
+	".groovy": slashStyle,
+	".hpp":    slashStyle,
+	".java":   slashStyle,
+	".js":     slashStyle,
+	".jsx":    slashStyle,
+	".kt":     slashStyle,
+	".m":      slashStyle,
+	".php":    slashStyle,
+	".proto":  slashStyle,
+	".rs":     slashStyle,
+	".scala":  slashStyle,
+	".scss":   slashStyle,
+	".swift":  slashStyle,
+	".ts":     slashStyle,
+	".tsx":    slashStyle,
+
+	// C89 has no line comment
+	".c":   cStyle,
+	".h":   cStyle,
+	".css": cStyle,
+
+	".bash":  hashStyle,
+	".bzl":   hashStyle,
+	".cmake": hashStyle,
+	".ex":    hashStyle,
+	".exs":   hashStyle,
+	".mk":    hashStyle,
+	".pl":    hashStyle,
+	".pm":    hashStyle,
+	".py":    hashStyle,
+	".r":     hashStyle,
+	".rb":    hashStyle,
+	".sh":    hashStyle,
+	".tf":    hashStyle,
+	".toml":  hashStyle,
+	".yaml":  hashStyle,
+	".yml":   hashStyle,
+	".zsh":   hashStyle,
+
+	".hs":  dashStyle,
+	".lua": dashStyle,
+	".sql": dashStyle,
+
+	".clj":  semiStyle,
+	".el":   semiStyle,
+	".lisp": semiStyle,
+
+	".html": markupStyle,
+	".vue":  markupStyle,
+	".xml":  markupStyle,
+}
+
+// commentStylesByName are comment syntax of files without extension.
+var commentStylesByName = map[string]commentStyle{
+	"Dockerfile":     hashStyle,
+	"Makefile":       hashStyle,
+	"Rakefile":       hashStyle,
+	"BUILD":          hashStyle,
+	"CMakeLists.txt": hashStyle,
+}
+
+// commentStyleFor returns comment syntax for the file. It returns false
+// when the file is not known as source file.
+func commentStyleFor(path string) (commentStyle, bool) {
+	base := filepath.Base(path)
+	if style, ok := commentStylesByName[base]; ok {
+		return style, true
+	}
+
+	style, ok := commentStyles[strings.ToLower(filepath.Ext(base))]
+	return style, ok
+}
+
+// comment converts text to comment.
+func (s commentStyle) comment(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+
+	var buf []string
+	if s.line == "" {
+		buf = append(buf, s.blockStart)
+	}
+
+	for _, l := range lines {
+		prefix := s.line
+		if prefix == "" {
+			prefix = s.blockMiddle
+		}
+
+		if l == "" {
+			buf = append(buf, strings.TrimRight(prefix, " "))
+			continue
+		}
+		buf = append(buf, prefix+" "+l)
+	}
+
+	if s.line == "" {
+		buf = append(buf, s.blockEnd)
+	}
+
+	return strings.Join(buf, "\n") + "\n"
+}
+
+// uncomment removes comment syntax from line. It's used for reading
+// license header in source file regardless of its syntax.
+func uncomment(line string) string {
+	line = strings.TrimSpace(line)
+	for _, s := range []string{"<!--", "-->", "/*", "*/", "//", ";;", "--", "#", "*"} {
+		line = strings.TrimSpace(strings.TrimPrefix(line, s))
+	}
+	for _, s := range []string{"*/", "-->"} {
+		line = strings.TrimSpace(strings.TrimSuffix(line, s))
+	}
+	return line
+}
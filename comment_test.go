@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCommentStyleFor(t *testing.T) {
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{"main.c", "/*\n * Copyright (c) 2015 tcnksm\n *\n * MIT\n */\n"},
+		{"include/main.h", "/*\n * Copyright (c) 2015 tcnksm\n *\n * MIT\n */\n"},
+		{"main.cpp", "// Copyright (c) 2015 tcnksm\n//\n// MIT\n"},
+		{"main.hpp", "// Copyright (c) 2015 tcnksm\n//\n// MIT\n"},
+		{"Makefile", "# Copyright (c) 2015 tcnksm\n#\n# MIT\n"},
+	}
+
+	for _, tc := range cases {
+		style, ok := commentStyleFor(tc.path)
+		if !ok {
+			t.Errorf("%s: expected comment style to be found", tc.path)
+			continue
+		}
+
+		if got := style.comment("Copyright (c) 2015 tcnksm\n\nMIT\n"); got != tc.expected {
+			t.Errorf("%s: expected %q to eq %q", tc.path, got, tc.expected)
+		}
+	}
+
+	if _, ok := commentStyleFor("LICENSE"); ok {
+		t.Errorf("expected LICENSE not to be source file")
+	}
+}
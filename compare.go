@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// CheckMark is shown in the table when LICENSE has the rule.
+const CheckMark = "✓"
+
+// Rules in the order of choosealicense.com.
+var (
+	permissionRules = []string{"commercial-use", "modifications", "distribution", "patent-use", "private-use"}
+	conditionRules  = []string{"disclose-source", "include-copyright", "include-copyright--source", "network-use-disclose", "same-license", "same-license--file", "same-license--library", "document-changes"}
+	limitationRules = []string{"liability", "patent-use", "trademark-use", "warranty"}
+)
+
+// orderRules returns rules which any of licenses has in the order of
+// known rules. Unknown rules follow them.
+func orderRules(known []string, licenses []*License, rulesOf func(*License) []string) []string {
+	var ordered []string
+	for _, r := range known {
+		for _, l := range licenses {
+			if contains(rulesOf(l), r) {
+				ordered = append(ordered, r)
+				break
+			}
+		}
+	}
+
+	for _, l := range licenses {
+		for _, r := range rulesOf(l) {
+			if !contains(ordered, r) {
+				ordered = append(ordered, r)
+			}
+		}
+	}
+	return ordered
+}
+
+// compareTable returns rows of table which compares rules of licenses.
+// Each row is section, rule label and check marks for licenses.
+func compareTable(licenses []*License) [][]string {
+	var rows [][]string
+	for _, section := range []struct {
+		title   string
+		known   []string
+		rulesOf func(*License) []string
+	}{
+		{"Permissions", permissionRules, func(l *License) []string { return l.Permissions }},
+		{"Conditions", conditionRules, func(l *License) []string { return l.Conditions }},
+		{"Limitations", limitationRules, func(l *License) []string { return l.Limitations }},
+	} {
+		for i, r := range orderRules(section.known, licenses, section.rulesOf) {
+			title := ""
+			if i == 0 {
+				title = section.title
+			}
+
+			row := []string{title, ruleLabel(r)}
+			for _, l := range licenses {
+				mark := ""
+				if contains(section.rulesOf(l), r) {
+					mark = CheckMark
+				}
+				row = append(row, mark)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// runCompare runs `license compare` command. It compares permissions,
+// conditions and limitations of LICENSE side by side.
+func (cli *CLI) runCompare(args []string) int {
+	var o options
+
+	flags := flag.NewFlagSet(Name+" compare", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, compareHelpText)
+	}
+
+	o.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	if flags.NArg() < 2 {
+		fmt.Fprintf(cli.errStream, "At least 2 LICENSE keys are required\n")
+		return ExitCodeError
+	}
+
+	licenses := make([]*License, 0, flags.NArg())
+	for _, key := range flags.Args() {
+		license, err := cli.getLicenseMetadata(strings.ToLower(key), &o)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to get LICENSE: %s\n", err.Error())
+			return ExitCodeError
+		}
+
+		if !hasRules(license) {
+			fmt.Fprintf(cli.errStream, "Failed to compare LICENSE: %q has no permissions, conditions and limitations\n", license.Key)
+			return ExitCodeError
+		}
+		licenses = append(licenses, license)
+	}
+
+	outBuffer := new(bytes.Buffer)
+	table := tablewriter.NewWriter(outBuffer)
+
+	header := []string{"", "Rule"}
+	for _, l := range licenses {
+		header = append(header, l.Key)
+	}
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(compareTable(licenses))
+	table.Render()
+
+	outBuffer.WriteString("See more about these rules at http://choosealicense.com/appendix/\n")
+	fmt.Fprint(cli.outStream, outBuffer.String())
+
+	return ExitCodeOK
+}
+
+var compareHelpText = `Usage: license compare [option] KEY KEY...
+
+  Compare permissions, conditions and limitations of LICENSE
+  side by side like http://choosealicense.com/appendix/
+
+Options:
+
+  Options to fetch LICENSE (e.g., -offline, -no-cache) are the same as
+  generating LICENSE.
+`
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCompareTable(t *testing.T) {
+	mit, _ := newBundledSource().Get("mit")
+	apache, _ := newBundledSource().Get("apache-2.0")
+
+	rows := compareTable([]*License{mit, apache})
+	expected := map[string][]string{
+		"Commercial use": {CheckMark, CheckMark},
+		"Patent use":     {"", CheckMark},
+		"State changes":  {"", CheckMark},
+		"Trademark use":  {"", CheckMark},
+	}
+
+	for _, row := range rows {
+		marks, ok := expected[row[1]]
+		if !ok {
+			continue
+		}
+		if row[2] != marks[0] || row[3] != marks[1] {
+			t.Errorf("%s: expected %v to eq %v", row[1], row[2:], marks)
+		}
+		delete(expected, row[1])
+	}
+
+	if len(expected) != 0 {
+		t.Errorf("expected rows are missing: %v", expected)
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// compatLevel is how a dependency LICENSE can be used in the project.
+type compatLevel int
+
+const (
+	compatible compatLevel = iota
+	compatWarning
+	compatConflict
+)
+
+func (l compatLevel) String() string {
+	switch l {
+	case compatWarning:
+		return "WARNING"
+	case compatConflict:
+		return "CONFLICT"
+	default:
+		return "OK"
+	}
+}
+
+// compatRule is the rule of using a dependency LICENSE
+// in projects under the LICENSE.
+type compatRule struct {
+	projects []string
+	level    compatLevel
+	reason   string
+}
+
+// Groups of project LICENSE keys used in compatMatrix.
+var (
+	gplFamily      = []string{"gpl-2.0", "gpl-3.0", "agpl-3.0", "lgpl-2.1", "lgpl-3.0"}
+	permissiveOnly = []string{"mit", "bsd-2-clause", "bsd-3-clause", "bsl-1.0", "isc", "0bsd", "zlib", "unlicense", "cc0-1.0", "apache-2.0"}
+	nonGPL         = append([]string{"mpl-2.0", "epl-2.0"}, permissiveOnly...)
+	gpl2Only       = []string{"gpl-2.0", "lgpl-2.1"}
+)
+
+// compatMatrix is the compatibility matrix of LICENSE. Key of the map is
+// LICENSE key of the dependency and the value is rules for project LICENSE
+// keys. Dependencies can be used in projects which are not in the rules.
+// It covers only obvious cases and it's not legal advice.
+var compatMatrix = map[string][]compatRule{
+	// Permissive licenses can be used anywhere
+	"mit":          nil,
+	"bsd-2-clause": nil,
+	"bsd-3-clause": nil,
+	"bsl-1.0":      nil,
+	"isc":          nil,
+	"0bsd":         nil,
+	"zlib":         nil,
+	"unlicense":    nil,
+	"cc0-1.0":      nil,
+
+	"apache-2.0": {
+		{
+			projects: gpl2Only,
+			level:    compatConflict,
+			reason:   "Apache-2.0 has patent termination and indemnification provisions which GPL-2.0 and LGPL-2.1 don't allow",
+		},
+	},
+
+	"mpl-2.0": {
+		{
+			projects: permissiveOnly,
+			level:    compatWarning,
+			reason:   "MPL-2.0 files must stay under MPL-2.0 and their source must be available",
+		},
+	},
+
+	"epl-2.0": {
+		{
+			projects: gplFamily,
+			level:    compatConflict,
+			reason:   "EPL-2.0 is incompatible with GPL unless the code designates GPL as a Secondary License",
+		},
+		{
+			projects: permissiveOnly,
+			level:    compatWarning,
+			reason:   "EPL-2.0 code must stay under EPL-2.0 and its source must be available",
+		},
+	},
+
+	"lgpl-2.1": {
+		{
+			projects: nonGPL,
+			level:    compatWarning,
+			reason:   "LGPL-2.1 requires that users can replace the library, which is hard with static linking",
+		},
+	},
+
+	"lgpl-3.0": {
+		{
+			projects: gpl2Only,
+			level:    compatConflict,
+			reason:   "LGPL-3.0 is incompatible with GPL-2.0-only and LGPL-2.1-only",
+		},
+		{
+			projects: nonGPL,
+			level:    compatWarning,
+			reason:   "LGPL-3.0 requires that users can replace the library, which is hard with static linking",
+		},
+	},
+
+	"gpl-2.0": {
+		{
+			projects: []string{"gpl-3.0", "agpl-3.0"},
+			level:    compatWarning,
+			reason:   "GPL-2.0 code can be used only if it's licensed under 'GPL-2.0-or-later'",
+		},
+		{
+			projects: append([]string{"lgpl-2.1", "lgpl-3.0"}, nonGPL...),
+			level:    compatConflict,
+			reason:   "GPL-2.0 requires the whole work to be licensed under GPL-2.0",
+		},
+	},
+
+	"gpl-2.0-or-later": {
+		{
+			projects: append([]string{"lgpl-2.1", "lgpl-3.0"}, nonGPL...),
+			level:    compatConflict,
+			reason:   "GPL-2.0-or-later requires the whole work to be licensed under GPL",
+		},
+	},
+
+	"gpl-3.0": {
+		{
+			projects: append([]string{"gpl-2.0", "lgpl-2.1", "lgpl-3.0"}, nonGPL...),
+			level:    compatConflict,
+			reason:   "GPL-3.0 requires the whole work to be licensed under GPL-3.0",
+		},
+	},
+
+	"agpl-3.0": {
+		{
+			projects: append([]string{"gpl-2.0", "gpl-3.0", "lgpl-2.1", "lgpl-3.0"}, nonGPL...),
+			level:    compatConflict,
+			reason:   "AGPL-3.0 requires the whole work to be licensed under AGPL-3.0 and its source to be offered to users over network",
+		},
+	},
+}
+
+// normalizeCompatKey converts SPDX ID (e.g., GPL-3.0-only) to LICENSE key.
+// "-or-later" is kept only when it makes difference (GPL-2.0-or-later).
+func normalizeCompatKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	key = strings.TrimSuffix(key, "-only")
+	if _, ok := compatMatrix[key]; ok {
+		return key
+	}
+	return strings.TrimSuffix(key, "-or-later")
+}
+
+// checkCompat returns how dependency LICENSE dep can be used in project
+// LICENSE project.
+func checkCompat(project, dep string) (compatLevel, string) {
+	rules, ok := compatMatrix[dep]
+	if !ok {
+		return compatWarning, fmt.Sprintf("compatibility of %q is unknown", dep)
+	}
+
+	for _, r := range rules {
+		for _, p := range r.projects {
+			if p == project {
+				return r.level, r.reason
+			}
+		}
+	}
+	return compatible, ""
+}
+
+// compatDependency is a dependency which is checked by compat command.
+// Name is optional (e.g., module name).
+type compatDependency struct {
+	Name string
+	Key  string
+}
+
+// readCompatFile reads dependencies from r. Each line is either
+// "KEY" or "NAME KEY". Empty lines and lines start with '#' are ignored.
+func readCompatFile(r io.Reader) ([]compatDependency, error) {
+	var deps []compatDependency
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		dep := compatDependency{Key: fields[len(fields)-1]}
+		if len(fields) > 1 {
+			dep.Name = strings.Join(fields[:len(fields)-1], " ")
+		}
+		deps = append(deps, dep)
+	}
+
+	return deps, scanner.Err()
+}
+
+// runCompat runs `license compat` command. It checks LICENSE of
+// dependencies can be used in the project.
+func (cli *CLI) runCompat(args []string) int {
+	var (
+		file  string
+		debug bool
+	)
+
+	flags := flag.NewFlagSet(Name+" compat", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, compatHelpText)
+	}
+
+	flags.StringVar(&file, "file", "", "")
+	flags.BoolVar(&debug, "debug", false, "")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	if debug {
+		os.Setenv(EnvDebug, "1")
+	}
+
+	if flags.NArg() < 1 {
+		fmt.Fprintf(cli.errStream, "LICENSE key of the project is required\n")
+		return ExitCodeError
+	}
+
+	project := normalizeCompatKey(flags.Arg(0))
+	if _, ok := compatMatrix[project]; !ok {
+		fmt.Fprintf(cli.errStream, "Compatibility of %q is unknown\n", project)
+		return ExitCodeError
+	}
+
+	var deps []compatDependency
+	for _, key := range flags.Args()[1:] {
+		deps = append(deps, compatDependency{Key: key})
+	}
+
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to open %q: %s\n", file, err.Error())
+			return ExitCodeError
+		}
+		defer f.Close()
+
+		fileDeps, err := readCompatFile(f)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to read %q: %s\n", file, err.Error())
+			return ExitCodeError
+		}
+		deps = append(deps, fileDeps...)
+	}
+
+	if len(deps) == 0 {
+		fmt.Fprintf(cli.errStream, "LICENSE keys of dependencies are required: provide them as arguments or -file\n")
+		return ExitCodeError
+	}
+
+	var conflicts, warnings int
+	for _, d := range deps {
+		key := normalizeCompatKey(d.Key)
+		level, reason := checkCompat(project, key)
+		Debugf("%s (%s) in %s: %s", d.Name, key, project, level)
+
+		switch level {
+		case compatConflict:
+			conflicts++
+		case compatWarning:
+			warnings++
+		default:
+			continue
+		}
+
+		name := key
+		if d.Name != "" {
+			name = fmt.Sprintf("%s (%s)", d.Name, key)
+		}
+		fmt.Fprintf(cli.outStream, "%-8s %s in %s project: %s\n", level, name, project, reason)
+	}
+
+	fmt.Fprintf(cli.errStream, "====> %d conflicts, %d warnings in %d dependencies\n", conflicts, warnings, len(deps))
+	if conflicts > 0 {
+		return ExitCodeCheckFailed
+	}
+	return ExitCodeOK
+}
+
+var compatHelpText = `Usage: license compat [option] KEY [DEP_KEY...]
+
+  Check LICENSE of dependencies (DEP_KEY) can be used in the project
+  which LICENSE is KEY. Keys are the same as '-list' shows (SPDX ID like
+  'GPL-3.0-only' is also accepted). Each conflict is explained and it
+  exits with non-zero status if there is any conflict.
+
+  It's based on the built-in compatibility matrix which covers only
+  obvious cases (e.g., GPL-3.0 dependency in Apache-2.0 project).
+  It's not legal advice.
+
+Options:
+
+  -file=FILE          Read dependencies from FILE. Each line is either
+                      'DEP_KEY' or 'NAME DEP_KEY'. Lines start with '#'
+                      are ignored.
+`
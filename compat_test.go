@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCheckCompat(t *testing.T) {
+	cases := []struct {
+		project  string
+		dep      string
+		expected compatLevel
+	}{
+		{"apache-2.0", "mit", compatible},
+		{"apache-2.0", "gpl-3.0", compatConflict},
+		{"gpl-3.0", "apache-2.0", compatible},
+		{"gpl-2.0", "apache-2.0", compatConflict},
+		{"gpl-3.0", "gpl-2.0", compatWarning},
+		{"gpl-3.0", "GPL-2.0-or-later", compatible},
+		{"mit", "AGPL-3.0-only", compatConflict},
+		{"gpl-3.0", "agpl-3.0", compatConflict},
+		{"agpl-3.0", "gpl-3.0", compatible},
+		{"mit", "lgpl-3.0", compatWarning},
+		{"mit", "wtfpl", compatWarning},
+	}
+
+	for _, tc := range cases {
+		level, _ := checkCompat(tc.project, normalizeCompatKey(tc.dep))
+		if level != tc.expected {
+			t.Errorf("%s in %s: expected %s to eq %s", tc.dep, tc.project, level, tc.expected)
+		}
+	}
+}
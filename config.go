@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	// UserConfigPath is path (relative to home) of user-level config file
+	UserConfigPath = ".config/license/config.toml"
+
+	// RepoConfigName is file name of repository-level config file.
+	// It's searched from current directory to the repository root.
+	RepoConfigName = ".licenserc"
+)
+
+// Config is default values of options. It's read from user-level
+// config file and repository-level config file (both are TOML).
+type Config struct {
+	Author  string `toml:"author"`
+	Email   string `toml:"email"`
+	Project string `toml:"project"`
+
+	// Holders are copyright holders. It's used instead of Author
+	// when the project has multiple copyright holders.
+	Holders []string `toml:"holders"`
+
+	// Description is one line description of the project
+	Description string `toml:"description"`
+
+	// License is the default LICENSE key
+	License string `toml:"license"`
+
+	// Output is the default output file name
+	Output string `toml:"output"`
+
+	// Templates is directory of custom LICENSE templates
+	Templates string `toml:"templates"`
+
+	// NoCache disables using local cache.
+	// It's pointer to know whether it's set or not.
+	NoCache *bool `toml:"no-cache"`
+
+	// CacheDir is directory for caching LICENSE files
+	CacheDir string `toml:"cache-dir"`
+
+	// CacheDuration is duration for storing cache (e.g., "720h")
+	CacheDuration string `toml:"cache-duration"`
+}
+
+// loadConfig reads user-level config file in home and repository-level
+// config file found from dir, and merges them. Values in the repository
+// one take precedence over the user one. Config files don't need to exist.
+func loadConfig(home, dir string) (*Config, error) {
+	config := &Config{}
+
+	if path, ok := findRepoConfig(dir); ok {
+		repoConfig, err := readConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		config.merge(repoConfig)
+	}
+
+	userConfig, err := readConfig(filepath.Join(home, UserConfigPath))
+	if err != nil {
+		return nil, err
+	}
+	config.merge(userConfig)
+
+	return config, nil
+}
+
+// readConfig reads TOML config file. If it's not exist,
+// it returns empty Config.
+func readConfig(path string) (*Config, error) {
+	var config Config
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &config, nil
+	}
+
+	Debugf("Read config file: %s", path)
+	md, err := toml.DecodeFile(path, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %s", path, err.Error())
+	}
+
+	for _, k := range md.Undecoded() {
+		Debugf("Unknown key in config file %s: %s", path, k.String())
+	}
+
+	// Paths in config file can start with ~
+	for _, p := range []*string{&config.Templates, &config.CacheDir} {
+		if *p == "" {
+			continue
+		}
+		if *p, err = homedir.Expand(*p); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.CacheDuration != "" {
+		if _, err := time.ParseDuration(config.CacheDuration); err != nil {
+			return nil, fmt.Errorf("invalid cache-duration in %s: %s", path, err.Error())
+		}
+	}
+
+	return &config, nil
+}
+
+// findRepoConfig searches repository-level config file from dir to
+// its parents. It stops at the repository root (which has .git).
+func findRepoConfig(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		path := filepath.Join(dir, RepoConfigName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", false
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// merge fills values which are not set in c with other.
+func (c *Config) merge(other *Config) {
+	// Author and Holders are the same value in different forms
+	if c.Holders == nil && c.Author == "" {
+		c.Holders = other.Holders
+	}
+
+	for _, v := range []struct{ dst, src *string }{
+		{&c.Author, &other.Author},
+		{&c.Email, &other.Email},
+		{&c.Project, &other.Project},
+		{&c.Description, &other.Description},
+		{&c.License, &other.License},
+		{&c.Output, &other.Output},
+		{&c.Templates, &other.Templates},
+		{&c.CacheDir, &other.CacheDir},
+		{&c.CacheDuration, &other.CacheDuration},
+	} {
+		if *v.dst == "" {
+			*v.dst = *v.src
+		}
+	}
+
+	if c.NoCache == nil {
+		c.NoCache = other.NoCache
+	}
+}
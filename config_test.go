@@ -0,0 +1,68 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	home, repo := t.TempDir(), t.TempDir()
+
+	userConfig := filepath.Join(home, UserConfigPath)
+	if err := os.MkdirAll(filepath.Dir(userConfig), 0777); err != nil {
+		t.Fatal(err)
+	}
+	user := `
+author = "user"
+email = "user@example.com"
+license = "mit"
+no-cache = true
+cache-dir = "~/cache"
+`
+	if err := ioutil.WriteFile(userConfig, []byte(user), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Repository-level config is searched from sub directory
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(repo, "sub")
+	if err := os.MkdirAll(sub, 0777); err != nil {
+		t.Fatal(err)
+	}
+	rc := `
+author = "repo"
+project = "license"
+license = "apache-2.0"
+`
+	if err := ioutil.WriteFile(filepath.Join(repo, RepoConfigName), []byte(rc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadConfig(home, sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Author != "repo" {
+		t.Errorf("expected %q to eq %q", config.Author, "repo")
+	}
+	if config.Email != "user@example.com" {
+		t.Errorf("expected %q to eq %q", config.Email, "user@example.com")
+	}
+	if config.Project != "license" {
+		t.Errorf("expected %q to eq %q", config.Project, "license")
+	}
+	if config.License != "apache-2.0" {
+		t.Errorf("expected %q to eq %q", config.License, "apache-2.0")
+	}
+	if config.NoCache == nil || !*config.NoCache {
+		t.Errorf("expected no-cache to be true")
+	}
+	if config.CacheDir == "~/cache" {
+		t.Errorf("expected %q to be expanded", config.CacheDir)
+	}
+}
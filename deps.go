@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/olekukonko/tablewriter"
+)
+
+// module is Go module which the project depends on.
+type module struct {
+	Path    string
+	Version string
+
+	// Dir is the directory of module source on the local disk.
+	// It's empty when the module is not downloaded.
+	Dir string
+}
+
+// dependency is a module and its LICENSE.
+type dependency struct {
+	module
+	detection *Detection
+	text      []byte
+}
+
+// stripComment removes "//" comment of go.mod line.
+func stripComment(line string) string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+// parseGoMod reads require and replace directives in go.mod. Modules
+// replaced with local directory have Dir (relative to go.mod).
+func parseGoMod(r io.Reader) ([]module, error) {
+	var (
+		modules  []module
+		replaces = map[string]module{}
+		block    string
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := stripComment(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if block != "" {
+			if line == ")" {
+				block = ""
+				continue
+			}
+			line = block + " " + line
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+
+		switch fields[0] {
+		case "require":
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("invalid require: %s", line)
+			}
+			modules = append(modules, module{Path: fields[1], Version: fields[2]})
+		case "replace":
+			i := indexOf(fields, "=>")
+			if i < 0 || i+1 >= len(fields) {
+				return nil, fmt.Errorf("invalid replace: %s", line)
+			}
+
+			m := module{Path: fields[i+1]}
+			if i+2 < len(fields) {
+				m.Version = fields[i+2]
+			} else {
+				m.Dir = fields[i+1]
+			}
+			replaces[fields[1]] = m
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, m := range modules {
+		if r, ok := replaces[m.Path]; ok {
+			modules[i].Version = r.Version
+			modules[i].Dir = r.Dir
+			if r.Dir == "" && r.Path != m.Path {
+				// Source is downloaded as replacement module
+				modules[i].Dir = moduleCacheDir(r.Path, r.Version)
+			}
+		}
+	}
+
+	return modules, nil
+}
+
+// parseModulesTxt reads vendor/modules.txt. Dir of each module is
+// relative to vendor directory.
+func parseModulesTxt(r io.Reader) ([]module, error) {
+	var modules []module
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "# ") {
+			continue
+		}
+
+		fields := strings.Fields(line[2:])
+		if len(fields) < 2 || strings.HasPrefix(fields[1], "=>") {
+			// Replaced module without version in go.mod
+			if len(fields) > 0 {
+				modules = append(modules, module{Path: fields[0], Dir: fields[0]})
+			}
+			continue
+		}
+		modules = append(modules, module{Path: fields[0], Version: fields[1], Dir: fields[0]})
+	}
+
+	return modules, scanner.Err()
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// escapeModulePath escapes module path or version for the module cache,
+// upper case letter is replaced with '!' and lower case one.
+// See https://golang.org/ref/mod#module-cache
+func escapeModulePath(path string) string {
+	var buf strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			buf.WriteRune('!')
+			buf.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// moduleCacheRoot returns the module cache directory. It's GOMODCACHE
+// or GOPATH/pkg/mod (by default, ~/go/pkg/mod).
+func moduleCacheRoot() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+
+	gopath := filepath.SplitList(os.Getenv("GOPATH"))
+	if len(gopath) > 0 && gopath[0] != "" {
+		return filepath.Join(gopath[0], "pkg", "mod")
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, "go", "pkg", "mod")
+}
+
+// moduleCacheDir returns the directory of module in the module cache.
+func moduleCacheDir(path, version string) string {
+	return filepath.Join(moduleCacheRoot(), escapeModulePath(path)+"@"+escapeModulePath(version))
+}
+
+// readModules returns dependencies of the project in dir. vendor/modules.txt
+// is preferred to go.mod if it exists.
+func readModules(dir string) ([]module, error) {
+	vendor := filepath.Join(dir, "vendor")
+	if f, err := os.Open(filepath.Join(vendor, "modules.txt")); err == nil {
+		defer f.Close()
+		Debugf("Read %s", f.Name())
+
+		modules, err := parseModulesTxt(f)
+		if err != nil {
+			return nil, err
+		}
+		for i := range modules {
+			modules[i].Dir = filepath.Join(vendor, filepath.FromSlash(modules[i].Dir))
+		}
+		return modules, nil
+	}
+
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	Debugf("Read %s", f.Name())
+
+	modules, err := parseGoMod(f)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range modules {
+		switch {
+		case m.Dir == "":
+			modules[i].Dir = moduleCacheDir(m.Path, m.Version)
+		case !filepath.IsAbs(m.Dir) && strings.HasPrefix(m.Dir, "."):
+			modules[i].Dir = filepath.Join(dir, filepath.FromSlash(m.Dir))
+		}
+	}
+	return modules, nil
+}
+
+// detectDependency detects LICENSE of module from its source on disk.
+func detectDependency(m module, corpus, exceptions []*License) (*dependency, error) {
+	dep := &dependency{module: m}
+
+	if _, err := os.Stat(m.Dir); err != nil {
+		return dep, fmt.Errorf("source of %s is not found (run 'go mod download')", m.Path)
+	}
+
+	file, ok := findLicenseFile(m.Dir)
+	if !ok {
+		return dep, fmt.Errorf("LICENSE file of %s is not found", m.Path)
+	}
+
+	d, err := detectLicense(file, corpus, exceptions)
+	if err != nil {
+		return dep, err
+	}
+	dep.detection = d
+
+	dep.text, err = ioutil.ReadFile(file)
+	return dep, err
+}
+
+// licenseName returns LICENSE name of dependency for report.
+func (d *dependency) licenseName() (string, string) {
+	switch {
+	case d.detection == nil:
+		return "-", "not found"
+	case d.detection.Key == "":
+		return "unknown", fmt.Sprintf("(closest: %s)", d.detection.Closest)
+	case d.detection.Exception != "":
+		return d.detection.Key + " WITH " + d.detection.Exception, d.detection.Name
+	default:
+		return d.detection.Key, d.detection.Name
+	}
+}
+
+// writeNotices writes third-party notices which include LICENSE text
+// of every dependency.
+func writeNotices(w io.Writer, deps []*dependency) {
+	separator := strings.Repeat("=", 80)
+
+	fmt.Fprintf(w, "This software includes the following third-party modules.\n")
+	for _, d := range deps {
+		key, name := d.licenseName()
+		if d.detection != nil && d.detection.Key != "" {
+			name = fmt.Sprintf("%s (%s)", name, key)
+		}
+
+		fmt.Fprintf(w, "\n%s\n%s %s\nLicense: %s\n%s\n", separator, d.Path, d.Version, name, separator)
+		if len(d.text) == 0 {
+			fmt.Fprintf(w, "\nLICENSE file is not found.\n")
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\n", strings.TrimSpace(string(d.text)))
+	}
+}
+
+// runDeps runs `license deps` command. It shows LICENSE of Go modules
+// the project depends on.
+func (cli *CLI) runDeps(args []string) int {
+	var (
+		output string
+		force  bool
+		o      options
+	)
+
+	flags := flag.NewFlagSet(Name+" deps", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, depsHelpText)
+	}
+
+	flags.StringVar(&output, "output", "", "")
+	flags.BoolVar(&force, "force", false, "")
+	o.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	// Everything is on the local disk. Templates are taken from
+	// cache or bundled ones.
+	o.offline = true
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	if output != "" {
+		if _, err := os.Stat(output); !os.IsNotExist(err) && !force {
+			fmt.Fprintf(cli.errStream, "Cannot create file %q: file exists\n", output)
+			return ExitCodeError
+		}
+	}
+
+	modules, err := readModules(dir)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to read modules: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	corpus, err := cli.loadCorpus(&o)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to fetch LICENSE list: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	exceptions := cli.loadExceptions()
+
+	deps := make([]*dependency, 0, len(modules))
+	for _, m := range modules {
+		d, err := detectDependency(m, corpus, exceptions)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to detect LICENSE: %s\n", err.Error())
+		}
+		deps = append(deps, d)
+	}
+
+	if output != "" {
+		var buf bytes.Buffer
+		writeNotices(&buf, deps)
+		if err := ioutil.WriteFile(output, buf.Bytes(), 0644); err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to write notices to %q: %s\n", output, err.Error())
+			return ExitCodeError
+		}
+		fmt.Fprintf(cli.errStream, "====> Successfully generated %q (%d modules)\n", output, len(deps))
+		return ExitCodeOK
+	}
+
+	outBuffer := new(bytes.Buffer)
+	table := tablewriter.NewWriter(outBuffer)
+	table.SetHeader([]string{"Module", "Version", "Key", "Name"})
+	for _, d := range deps {
+		key, name := d.licenseName()
+		table.Append([]string{d.Path, d.Version, key, name})
+	}
+	table.Render()
+
+	fmt.Fprint(cli.outStream, outBuffer.String())
+	return ExitCodeOK
+}
+
+var depsHelpText = `Usage: license deps [option] [DIR]
+
+  Show LICENSE of Go modules which the project in DIR (by default,
+  current directory) depends on. Modules are read from vendor/modules.txt
+  or go.mod, and their source is read from vendor directory or the module
+  cache (GOMODCACHE). LICENSE is detected like 'license detect'.
+  It never accesses the network.
+
+Options:
+
+  -output=NAME        Write third-party notices which include LICENSE
+                      text of every module to the file instead of showing
+                      the table (e.g., THIRD_PARTY_LICENSES, NOTICE).
+
+  -force              Replace the output file if exist.
+
+  Options to read LICENSE templates (e.g., -templates, -spdx) are the
+  same as generating LICENSE.
+`
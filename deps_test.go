@@ -0,0 +1,68 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	gomod := `module github.com/tcnksm/example
+
+go 1.16
+
+require github.com/BurntSushi/toml v1.2.1
+
+require (
+	github.com/mitchellh/go-homedir v1.1.0 // indirect
+	github.com/tcnksm/go-latest v0.0.0-20170313132115-e3007ae9052e
+	github.com/olekukonko/tablewriter v0.0.5
+)
+
+replace github.com/tcnksm/go-latest => ../go-latest
+`
+
+	t.Setenv("GOMODCACHE", "/cache")
+	modules, err := parseGoMod(strings.NewReader(gomod))
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	expected := []module{
+		{Path: "github.com/BurntSushi/toml", Version: "v1.2.1"},
+		{Path: "github.com/mitchellh/go-homedir", Version: "v1.1.0"},
+		{Path: "github.com/tcnksm/go-latest", Dir: "../go-latest"},
+		{Path: "github.com/olekukonko/tablewriter", Version: "v0.0.5"},
+	}
+	if !reflect.DeepEqual(modules, expected) {
+		t.Errorf("expected %v to eq %v", modules, expected)
+	}
+
+	dir := moduleCacheDir(modules[0].Path, modules[0].Version)
+	if want := filepath.Join("/cache", "github.com/!burnt!sushi/toml@v1.2.1"); dir != want {
+		t.Errorf("expected %q to eq %q", dir, want)
+	}
+}
+
+func TestParseModulesTxt(t *testing.T) {
+	txt := `# github.com/mattn/go-runewidth v0.0.3
+## explicit
+github.com/mattn/go-runewidth
+# github.com/tcnksm/go-latest => ../go-latest
+github.com/tcnksm/go-latest
+`
+
+	modules, err := parseModulesTxt(strings.NewReader(txt))
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	expected := []module{
+		{Path: "github.com/mattn/go-runewidth", Version: "v0.0.3", Dir: "github.com/mattn/go-runewidth"},
+		{Path: "github.com/tcnksm/go-latest", Dir: "github.com/tcnksm/go-latest"},
+	}
+	if !reflect.DeepEqual(modules, expected) {
+		t.Errorf("expected %v to eq %v", modules, expected)
+	}
+}
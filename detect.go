@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// Detection is the result of detecting LICENSE of the project.
+// Key is empty when no LICENSE matches with enough confidence.
+type Detection struct {
+	Path       string  `json:"path"`
+	File       string  `json:"file"`
+	Key        string  `json:"key"`
+	Name       string  `json:"name"`
+	SPDXID     string  `json:"spdx_id"`
+	Confidence float64 `json:"confidence"`
+
+	// Exception is the key of LICENSE exception (e.g., Classpath
+	// exception) appended to LICENSE.
+	Exception string `json:"exception,omitempty"`
+
+	// Closest is the key of the most similar LICENSE
+	// even when its confidence is low.
+	Closest string `json:"closest,omitempty"`
+}
+
+// detectLicense detects LICENSE of path by comparing its LICENSE file with
+// corpus. path is either a project directory or LICENSE file itself.
+// If LICENSE file contains one of exceptions, it's compared with LICENSE
+// in corpus with the exception.
+func detectLicense(path string, corpus, exceptions []*License) (*Detection, error) {
+	file := path
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		var ok bool
+		file, ok = findLicenseFile(path)
+		if !ok {
+			return nil, fmt.Errorf("LICENSE file is not found in %s", path)
+		}
+	}
+
+	text, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Detection{Path: path, File: file}
+
+	exception := findException(normalizeText(string(text)), exceptions)
+	if exception != nil {
+		Debugf("%s contains exception %s", file, exception.Key)
+		d.Exception = exception.Key
+
+		withException := make([]*License, 0, len(corpus))
+		for _, l := range corpus {
+			c := *l
+			c.Body = appendException(l.Body, exception)
+			withException = append(withException, &c)
+		}
+		corpus = withException
+	}
+
+	matches := matchLicense(string(text), corpus)
+	if len(matches) == 0 {
+		return d, nil
+	}
+
+	best := matches[0]
+	Debugf("%s matches %s (%.2f)", file, best.License.Key, best.Similarity)
+
+	d.Confidence = best.Similarity
+	d.Closest = best.License.Key
+	if best.Similarity >= MatchThreshold {
+		d.Key = best.License.Key
+		d.Name = best.License.Name
+		d.SPDXID = best.License.SPDXID
+	}
+
+	return d, nil
+}
+
+// runDetect runs `license detect` command. It detects LICENSE of
+// existing projects.
+func (cli *CLI) runDetect(args []string) int {
+	var (
+		jsonFormat bool
+		o          options
+	)
+
+	flags := flag.NewFlagSet(Name+" detect", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, detectHelpText)
+	}
+
+	flags.BoolVar(&jsonFormat, "json", false, "")
+	o.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	corpus, err := cli.loadCorpus(&o)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to fetch LICENSE list: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	exceptions := cli.loadExceptions()
+
+	exitCode := ExitCodeOK
+	detections := make([]*Detection, 0, len(paths))
+	for _, path := range paths {
+		d, err := detectLicense(path, corpus, exceptions)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to detect LICENSE: %s\n", err.Error())
+			exitCode = ExitCodeError
+			continue
+		}
+		detections = append(detections, d)
+	}
+
+	if jsonFormat {
+		enc := json.NewEncoder(cli.outStream)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(detections); err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to print result: %s\n", err.Error())
+			return ExitCodeError
+		}
+		return exitCode
+	}
+
+	outBuffer := new(bytes.Buffer)
+	table := tablewriter.NewWriter(outBuffer)
+	table.SetHeader([]string{"Path", "File", "Key", "Name", "Confidence"})
+	for _, d := range detections {
+		key, name := d.Key, d.Name
+		if key == "" {
+			key = "unknown"
+			name = fmt.Sprintf("(closest: %s)", d.Closest)
+		}
+		if d.Exception != "" {
+			key += " WITH " + d.Exception
+		}
+		table.Append([]string{d.Path, d.File, key, name, fmt.Sprintf("%.1f%%", d.Confidence*100)})
+	}
+	table.Render()
+
+	fmt.Fprint(cli.outStream, outBuffer.String())
+	return exitCode
+}
+
+var detectHelpText = `Usage: license detect [option] [PATH...]
+
+  Detect LICENSE of existing projects in PATH (by default, current
+  directory). PATH is either a project directory or LICENSE file.
+  LICENSE file (e.g., LICENSE, COPYING) is compared with all LICENSE
+  templates regardless of whitespace, punctuation, copyright lines and
+  placeholders, and the most similar one is shown with its confidence.
+  LICENSE exception (e.g., Classpath exception) in it is also detected.
+  It's 'unknown' when the confidence is lower than 0.95.
+
+Options:
+
+  -json               Print result as JSON.
+
+  Options to fetch LICENSE (e.g., -offline, -no-cache) are the same as
+  generating LICENSE. Templates are read from cache if available.
+`
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DiffContext is the number of context sentences around changes.
+const DiffContext = 2
+
+// sentence is a sentence of LICENSE template. If it has unresolved
+// placeholders, pattern matches any replacement of them.
+type sentence struct {
+	text    string
+	pattern *regexp.Regexp
+}
+
+// equal returns true if s is the same as sentence of LICENSE file.
+func (s sentence) equal(text string) bool {
+	if s.pattern != nil {
+		return s.pattern.MatchString(text)
+	}
+	return s.text == text
+}
+
+// paragraphReg matches blank lines between paragraphs.
+var paragraphReg = regexp.MustCompile(`\n[ \t]*\n`)
+
+// splitSentences removes copyright lines and splits text into
+// sentences. Paragraphs (e.g., title) are also split and whitespace
+// is normalized.
+func splitSentences(text string) []string {
+	text = copyrightLineReg.ReplaceAllString(text, "")
+
+	var sentences []string
+	for _, p := range paragraphReg.Split(text, -1) {
+		var words []string
+		for _, w := range strings.Fields(p) {
+			words = append(words, w)
+			if strings.ContainsAny(w[len(w)-1:], ".;:!?") {
+				sentences = append(sentences, strings.Join(words, " "))
+				words = nil
+			}
+		}
+		if len(words) > 0 {
+			sentences = append(sentences, strings.Join(words, " "))
+		}
+	}
+	return sentences
+}
+
+// templateSentences splits LICENSE template into sentences.
+// Placeholders match any text.
+func templateSentences(text string) []sentence {
+	placeholders := allPlaceholders()
+
+	var sentences []sentence
+	for _, s := range splitSentences(text) {
+		sen := sentence{text: s}
+		if len(findPlaceholders(s, placeholders)) > 0 {
+			pattern := regexp.QuoteMeta(s)
+			for _, p := range placeholders {
+				pattern = strings.Replace(pattern, regexp.QuoteMeta(p), ".+?", -1)
+			}
+			sen.pattern = regexp.MustCompile("^" + pattern + "$")
+		}
+		sentences = append(sentences, sen)
+	}
+	return sentences
+}
+
+// diffLine is a line of diff. op is ' ' (same), '-' (only in
+// template) or '+' (only in file).
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffSentences returns the shortest edit from template to file
+// by the longest common subsequence of sentences.
+func diffSentences(template []sentence, file []string) []diffLine {
+	n, m := len(template), len(file)
+
+	// lcs[i][j] is the length of LCS of template[i:] and file[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if template[i].equal(file[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case template[i].equal(file[j]):
+			lines = append(lines, diffLine{' ', file[j]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', template[i].text})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', file[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, diffLine{'-', template[i].text})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, diffLine{'+', file[j]})
+	}
+	return lines
+}
+
+// unifiedDiff formats diff lines as unified diff. Line numbers are
+// sentence numbers. It returns empty string if there is no change.
+func unifiedDiff(lines []diffLine, from, to string) string {
+	var buf bytes.Buffer
+
+	// Find ranges of hunks which include changes and their context
+	var start, end = -1, -1
+	var hunks [][2]int
+	for i, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+
+		s, e := i-DiffContext, i+DiffContext+1
+		if s < 0 {
+			s = 0
+		}
+		if e > len(lines) {
+			e = len(lines)
+		}
+
+		if start >= 0 && s <= end {
+			end = e
+			continue
+		}
+		if start >= 0 {
+			hunks = append(hunks, [2]int{start, end})
+		}
+		start, end = s, e
+	}
+	if start < 0 {
+		return ""
+	}
+	hunks = append(hunks, [2]int{start, end})
+
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", from, to)
+	for _, h := range hunks {
+		// Sentence numbers (1-origin) at the start of the hunk
+		a, b := 1, 1
+		for _, l := range lines[:h[0]] {
+			if l.op != '+' {
+				a++
+			}
+			if l.op != '-' {
+				b++
+			}
+		}
+
+		var aLen, bLen int
+		for _, l := range lines[h[0]:h[1]] {
+			if l.op != '+' {
+				aLen++
+			}
+			if l.op != '-' {
+				bLen++
+			}
+		}
+
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", a, aLen, b, bLen)
+		for _, l := range lines[h[0]:h[1]] {
+			fmt.Fprintf(&buf, "%c%s\n", l.op, l.text)
+		}
+	}
+	return buf.String()
+}
+
+// runDiff runs `license diff` command. It shows changes of LICENSE file
+// from the canonical LICENSE text.
+func (cli *CLI) runDiff(args []string) int {
+	var o options
+
+	flags := flag.NewFlagSet(Name+" diff", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, diffHelpText)
+	}
+
+	o.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	path, key := ".", ""
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		// The argument is KEY if it's not a file
+		if _, err := os.Stat(flags.Arg(0)); err != nil {
+			key = flags.Arg(0)
+		} else {
+			path = flags.Arg(0)
+		}
+	case 2:
+		path, key = flags.Arg(0), flags.Arg(1)
+	default:
+		fmt.Fprintf(cli.errStream, "Invalid arguments\n")
+		return ExitCodeError
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		file, ok := findLicenseFile(path)
+		if !ok {
+			fmt.Fprintf(cli.errStream, "LICENSE file is not found in %s\n", path)
+			return ExitCodeError
+		}
+		path = file
+	}
+
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to read LICENSE file: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	if key == "" {
+		corpus, err := cli.loadCorpus(&o)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to fetch LICENSE list: %s\n", err.Error())
+			return ExitCodeError
+		}
+
+		matches := matchLicense(string(text), corpus)
+		if len(matches) == 0 {
+			fmt.Fprintf(cli.errStream, "Failed to detect LICENSE of %s: use KEY argument\n", path)
+			return ExitCodeError
+		}
+		key = matches[0].License.Key
+		fmt.Fprintf(cli.errStream, "====> Compare %s with %s (similarity %.1f%%)\n", path, key, matches[0].Similarity*100)
+	}
+	key = strings.ToLower(key)
+
+	license, _, err := cli.getLicense(key, &o)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to get LICENSE: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	// Placeholders are never replaced, they match any text of the file
+	lines := diffSentences(templateSentences(license.Body), splitSentences(string(text)))
+
+	diff := unifiedDiff(lines, key, path)
+	if diff == "" {
+		fmt.Fprintf(cli.errStream, "====> %s is the same as %s\n", path, key)
+		return ExitCodeOK
+	}
+
+	fmt.Fprint(cli.outStream, diff)
+	return ExitCodeCheckFailed
+}
+
+var diffHelpText = `Usage: license diff [option] [PATH] [KEY]
+
+  Show changes of LICENSE file in PATH (by default, LICENSE in current
+  directory) from the canonical text of KEY as unified diff. If KEY is
+  not provided, the most similar LICENSE is used. Placeholders in the
+  canonical text (e.g., [year]) match any text.
+
+  Whitespace and copyright lines are ignored and texts are compared
+  sentence by sentence, so the diff shows only substantive changes.
+  It exits with non-zero status if there is any change.
+
+Options:
+
+  Options to fetch LICENSE (e.g., -offline, -templates) are the same
+  as generating LICENSE.
+`
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestDiffSentences(t *testing.T) {
+	template := `Copyright (c) [year] [fullname]
+
+Permission is hereby granted, free of charge, to any person.
+The above copyright notice shall be included.
+[project] is provided "AS IS", without warranty of any kind.
+`
+
+	file := `Copyright (c) 2015 Taichi Nakashima
+
+Permission is hereby granted,   free of charge,
+to any person.
+The above copyright notice shall be included in all copies.
+license is provided "AS IS", without warranty of any kind.
+`
+
+	lines := diffSentences(templateSentences(template), splitSentences(file))
+	diff := unifiedDiff(lines, "mit", "LICENSE")
+
+	expected := `--- mit
++++ LICENSE
+@@ -1,3 +1,3 @@
+ Permission is hereby granted, free of charge, to any person.
+-The above copyright notice shall be included.
++The above copyright notice shall be included in all copies.
+ license is provided "AS IS", without warranty of any kind.
+`
+	if diff != expected {
+		t.Errorf("expected %q to eq %q", diff, expected)
+	}
+
+	lines = diffSentences(templateSentences(template), splitSentences(template))
+	if diff := unifiedDiff(lines, "mit", "LICENSE"); diff != "" {
+		t.Errorf("expected no diff, got %q", diff)
+	}
+
+	if !strings.HasPrefix(splitSentences(file)[0], "Permission") {
+		t.Errorf("expected copyright line to be removed: %q", splitSentences(file)[0])
+	}
+}
+
+func TestDiffSentences_bundled(t *testing.T) {
+	list, err := newBundledSource().List()
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	cli := &CLI{errStream: ioutil.Discard, nonInteractive: true}
+	opts := placeholderOptions{
+		year:        "2015",
+		holders:     []string{"Taichi Nakashima"},
+		email:       "nsd22843@gmail.com",
+		project:     "license",
+		description: "Generate LICENSE file",
+	}
+
+	for _, l := range list {
+		// Generated LICENSE is the same as the canonical text
+		generated, _ := cli.ReplacePlaceholders(l.Body, l.Key, opts)
+		for _, text := range []string{l.Body, generated} {
+			lines := diffSentences(templateSentences(l.Body), splitSentences(text))
+			if diff := unifiedDiff(lines, l.Key, "LICENSE"); diff != "" {
+				t.Errorf("expected no diff of %s, got %q", l.Key, diff)
+			}
+		}
+	}
+}
+
+func TestDiffSentences_listItem(t *testing.T) {
+	license, err := newBundledSource().Get("apache-2.0")
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	// Clause (c) of section 4 must not be ignored as copyright line
+	text := strings.Replace(license.Body, "(c) You must retain", "(c) You may remove", 1)
+	lines := diffSentences(templateSentences(license.Body), splitSentences(text))
+	diff := unifiedDiff(lines, "apache-2.0", "LICENSE")
+
+	expected := "+(c) You may remove, in the Source form"
+	if !strings.Contains(diff, expected) {
+		t.Errorf("expected %q to contain %q", diff, expected)
+	}
+}
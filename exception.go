@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+)
+
+// appendException appends text of LICENSE exception (e.g., Classpath
+// exception) to LICENSE body like "GPL-2.0 WITH Classpath-exception-2.0".
+func appendException(body string, exception *License) string {
+	return strings.TrimRight(body, "\n") + "\n\n" + exception.Body
+}
+
+// loadExceptions returns all LICENSE exceptions with body. Exceptions
+// are optional for detection, so it returns nil if they are not available.
+func (cli *CLI) loadExceptions() []*License {
+	if cli.exceptions == nil {
+		return nil
+	}
+
+	exceptions, err := cli.exceptions.List()
+	if err != nil {
+		Debugf("Failed to list LICENSE exceptions: %s", err.Error())
+		return nil
+	}
+	return exceptions
+}
+
+// findException returns LICENSE exception whose text is contained in
+// text (normalized words). It returns nil if there is no exception.
+func findException(words []string, exceptions []*License) *License {
+	for _, e := range exceptions {
+		if containment(normalizeText(e.Body), words) >= MatchThreshold {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// licenseExpr is a node of SPDX license expression, e.g., "MIT OR Apache-2.0"
+// See https://spdx.github.io/spdx-spec/SPDX-license-expressions/
+type licenseExpr interface {
+	// licenses returns LICENSE in the expression from left to right.
+	// The same LICENSE is returned only once.
+	licenses() []*licenseRef
+
+	// format returns the expression. ID of LICENSE and exception
+	// is given by id from its key. Key of LICENSE with "or later"
+	// has "+" suffix, e.g., "gpl-2.0+".
+	format(id func(key string) string) string
+}
+
+// licenseRef is LICENSE in the expression, e.g., "Apache-2.0" or "GPL-2.0+".
+type licenseRef struct {
+	// key is LICENSE key (lower case ID)
+	key string
+
+	// orLater is true when the ID has "+" (or any later version)
+	orLater bool
+
+	// exception is key of exception added by WITH
+	exception string
+}
+
+func (r *licenseRef) licenses() []*licenseRef {
+	return []*licenseRef{r}
+}
+
+func (r *licenseRef) format(id func(key string) string) string {
+	if r.orLater {
+		return id(r.key + "+")
+	}
+	return id(r.key)
+}
+
+// withExpr is LICENSE with exception, e.g., "GPL-2.0 WITH Classpath-exception-2.0".
+type withExpr struct {
+	license *licenseRef
+
+	// exception is lower case ID of the exception
+	exception string
+}
+
+func (w *withExpr) licenses() []*licenseRef {
+	ref := *w.license
+	ref.exception = w.exception
+	return []*licenseRef{&ref}
+}
+
+func (w *withExpr) format(id func(key string) string) string {
+	return w.license.format(id) + " WITH " + id(w.exception)
+}
+
+// binaryExpr is a compound expression with AND or OR operator.
+type binaryExpr struct {
+	op          string
+	left, right licenseExpr
+}
+
+func (b *binaryExpr) licenses() []*licenseRef {
+	var refs []*licenseRef
+	seen := make(map[string]bool)
+	for _, r := range append(b.left.licenses(), b.right.licenses()...) {
+		if !seen[r.key] {
+			seen[r.key] = true
+			refs = append(refs, r)
+		}
+	}
+	return refs
+}
+
+func (b *binaryExpr) format(id func(key string) string) string {
+	operand := func(e licenseExpr) string {
+		// Parenthesize compound expression with different operator
+		if c, ok := e.(*binaryExpr); ok && c.op != b.op {
+			return "(" + c.format(id) + ")"
+		}
+		return e.format(id)
+	}
+	return operand(b.left) + " " + b.op + " " + operand(b.right)
+}
+
+// operands returns operands of the same operator in the expression
+// e.g., operands of "MIT OR (Apache-2.0 OR ISC)" are all LICENSE.
+func (b *binaryExpr) operands() []licenseExpr {
+	var operands []licenseExpr
+	for _, e := range []licenseExpr{b.left, b.right} {
+		if c, ok := e.(*binaryExpr); ok && c.op == b.op {
+			operands = append(operands, c.operands()...)
+			continue
+		}
+		operands = append(operands, e)
+	}
+	return operands
+}
+
+// exprTokenReg matches tokens of expression: parentheses and words.
+var exprTokenReg = regexp.MustCompile(`[()]|[^\s()]+`)
+
+// exprParser parses SPDX license expression. Precedence of operators
+// is WITH > AND > OR. Operators are case insensitive.
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseExpression parses SPDX license expression, e.g., "mit OR apache-2.0".
+// A single LICENSE key is also a valid expression.
+func parseExpression(s string) (licenseExpr, error) {
+	p := &exprParser{tokens: exprTokenReg.FindAllString(s, -1)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+	return expr, nil
+}
+
+// peek returns the next token without consuming it.
+func (p *exprParser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+// next consumes the next token.
+func (p *exprParser) next() (string, bool) {
+	tok, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return tok, ok
+}
+
+// isOperator returns true if the next token is operator op.
+func (p *exprParser) isOperator(op string) bool {
+	tok, ok := p.peek()
+	return ok && strings.ToUpper(tok) == op
+}
+
+func (p *exprParser) parseOr() (licenseExpr, error) {
+	return p.parseBinary("OR", p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (licenseExpr, error) {
+	return p.parseBinary("AND", p.parseWith)
+}
+
+// parseBinary parses operands joined by op (left associative).
+func (p *exprParser) parseBinary(op string, operand func() (licenseExpr, error)) (licenseExpr, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator(op) {
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseWith() (licenseExpr, error) {
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.isOperator("WITH") {
+		return expr, nil
+	}
+	p.next()
+
+	ref, ok := expr.(*licenseRef)
+	if !ok {
+		return nil, fmt.Errorf("WITH must follow a LICENSE")
+	}
+
+	tok, ok := p.next()
+	if !ok || isExprKeyword(tok) {
+		return nil, fmt.Errorf("exception is expected after WITH")
+	}
+	return &withExpr{license: ref, exception: strings.ToLower(tok)}, nil
+}
+
+func (p *exprParser) parsePrimary() (licenseExpr, error) {
+	tok, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	if tok == "(" {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok, ok := p.next(); !ok || tok != ")" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		return expr, nil
+	}
+
+	if isExprKeyword(tok) {
+		return nil, fmt.Errorf("LICENSE is expected but found %q", tok)
+	}
+
+	ref := &licenseRef{key: strings.ToLower(tok)}
+	if strings.HasSuffix(ref.key, "+") {
+		ref.key = strings.TrimSuffix(ref.key, "+")
+		ref.orLater = true
+	}
+	if ref.key == "" {
+		return nil, fmt.Errorf("LICENSE is expected but found %q", tok)
+	}
+	return ref, nil
+}
+
+// isExprKeyword returns true if tok is operator or parenthesis.
+func isExprKeyword(tok string) bool {
+	switch strings.ToUpper(tok) {
+	case "AND", "OR", "WITH", "(", ")":
+		return true
+	}
+	return false
+}
+
+// expressionFileNames returns file names of each LICENSE in expression,
+// e.g., LICENSE-MIT and LICENSE-APACHE. Version is omitted unless
+// it's necessary to distinguish them (e.g., LICENSE-GPL-2.0).
+func expressionFileNames(refs []*licenseRef) []string {
+	family := func(key string) string {
+		return strings.SplitN(key, "-", 2)[0]
+	}
+
+	count := make(map[string]int)
+	for _, r := range refs {
+		count[family(r.key)]++
+	}
+
+	names := make([]string, 0, len(refs))
+	for _, r := range refs {
+		name := family(r.key)
+		if count[name] > 1 {
+			name = r.key
+		}
+		names = append(names, "LICENSE-"+strings.ToUpper(name))
+	}
+	return names
+}
+
+// deprecatedSPDXIDs are deprecated SPDX IDs of GNU licenses, which are
+// still used by GitHub API and bundled templates. Package managers
+// (e.g., npm, Cargo) warn on them.
+var deprecatedSPDXIDs = []string{
+	"GPL-1.0", "GPL-2.0", "GPL-3.0",
+	"LGPL-2.0", "LGPL-2.1", "LGPL-3.0",
+	"AGPL-1.0", "AGPL-3.0",
+}
+
+// currentSPDXID returns the current SPDX ID of deprecated one, e.g.,
+// "GPL-3.0" to "GPL-3.0-only" and "GPL-3.0" with or later to
+// "GPL-3.0-or-later". Other IDs are returned as they are ("+" is added
+// when orLater is true).
+func currentSPDXID(id string, orLater bool) string {
+	if contains(deprecatedSPDXIDs, id) {
+		if orLater {
+			return id + "-or-later"
+		}
+		return id + "-only"
+	}
+	if orLater {
+		return id + "+"
+	}
+	return id
+}
+
+// sameSPDXID returns true if SPDX IDs a and b are the same LICENSE.
+// Case is ignored and deprecated IDs of GNU licenses are the same as
+// their "-only" and "-or-later" forms, e.g., "GPL-3.0" and
+// "GPL-3.0-or-later" since LICENSE file of them is the same.
+func sameSPDXID(a, b string) bool {
+	base := func(id string) string {
+		id = strings.ToUpper(id)
+		for _, suffix := range []string{"-ONLY", "-OR-LATER", "+"} {
+			if b := strings.TrimSuffix(id, suffix); b != id && contains(deprecatedSPDXIDs, b) {
+				return b
+			}
+		}
+		return id
+	}
+	return base(a) == base(b)
+}
+
+// spdxExpression returns the expression with SPDX ID of licenses and
+// exceptions (in the same order as expr.licenses()). Deprecated IDs are
+// replaced by the current ones (see currentSPDXID). It fails if SPDX ID
+// of any of them is unknown, then its key in upper case is used.
+func spdxExpression(expr licenseExpr, licenses, exceptions []*License) (string, error) {
+	ids := make(map[string]string)
+	for _, l := range append(licenses, exceptions...) {
+		if l != nil {
+			ids[l.Key] = l.SPDXID
+		}
+	}
+
+	var unknown []string
+	s := expr.format(func(key string) string {
+		orLater := strings.HasSuffix(key, "+")
+		key = strings.TrimSuffix(key, "+")
+		if id := ids[key]; id != "" {
+			return currentSPDXID(id, orLater)
+		}
+		unknown = append(unknown, key)
+		return currentSPDXID(strings.ToUpper(key), orLater)
+	})
+
+	if len(unknown) > 0 {
+		return s, fmt.Errorf("SPDX ID of %s is unknown", strings.Join(unknown, ", "))
+	}
+	return s, nil
+}
+
+// expressionText returns the top-level LICENSE text which explains
+// the expression and which file has each LICENSE. licenses, exceptions
+// (nil if LICENSE has no exception) and files are in the same order
+// as expr.licenses().
+func expressionText(expr licenseExpr, licenses, exceptions []*License, files []string) string {
+	refs := expr.licenses()
+
+	// Custom LICENSE without SPDX ID is shown by its key
+	id, _ := spdxExpression(expr, licenses, exceptions)
+
+	var buf bytes.Buffer
+	buf.WriteString("This project is licensed under the following SPDX license expression:\n\n")
+	fmt.Fprintf(&buf, "    %s\n\n", id)
+
+	// Explain simple expression like "MIT OR Apache-2.0" in words
+	explained := false
+	if b, ok := expr.(*binaryExpr); ok {
+		explained = true
+		for _, e := range b.operands() {
+			if _, ok := e.(*binaryExpr); ok {
+				explained = false
+			}
+		}
+
+		if explained && b.op == "OR" {
+			buf.WriteString("You may use it under the terms of either of the following LICENSE\nat your option:\n\n")
+		}
+		if explained && b.op == "AND" {
+			buf.WriteString("You must comply with all of the following LICENSE:\n\n")
+		}
+	}
+
+	if !explained {
+		buf.WriteString("The text of each LICENSE is in the following files:\n\n")
+	}
+
+	for i, r := range refs {
+		name := licenses[i].Name
+		if r.orLater {
+			name += " or any later version"
+		}
+		if exceptions[i] != nil {
+			name += " with " + exceptions[i].Name
+		}
+		fmt.Fprintf(&buf, "  * %s (%s)\n", name, files[i])
+	}
+	return buf.String()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	id := func(key string) string {
+		return strings.ToUpper(key)
+	}
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"mit", "MIT"},
+		{"mit OR apache-2.0", "MIT OR APACHE-2.0"},
+		{"MIT or Apache-2.0", "MIT OR APACHE-2.0"},
+		{"mit AND isc OR apache-2.0", "(MIT AND ISC) OR APACHE-2.0"},
+		{"mit AND (isc OR apache-2.0)", "MIT AND (ISC OR APACHE-2.0)"},
+		{"(mit OR isc) OR apache-2.0", "MIT OR ISC OR APACHE-2.0"},
+		{"gpl-2.0+ WITH Classpath-exception-2.0", "GPL-2.0+ WITH CLASSPATH-EXCEPTION-2.0"},
+		{"mit OR gpl-2.0 WITH classpath-exception-2.0", "MIT OR GPL-2.0 WITH CLASSPATH-EXCEPTION-2.0"},
+	}
+
+	for _, tc := range cases {
+		expr, err := parseExpression(tc.input)
+		if err != nil {
+			t.Fatalf("%q: err: %s", tc.input, err)
+		}
+		if got := expr.format(id); got != tc.expected {
+			t.Errorf("expected %q to eq %q", got, tc.expected)
+		}
+	}
+
+	for _, input := range []string{"", "OR", "mit OR", "mit apache-2.0", "(mit OR isc", "mit)", "(mit OR isc) WITH foo", "mit WITH"} {
+		if _, err := parseExpression(input); err == nil {
+			t.Errorf("expect %q to fail", input)
+		}
+	}
+}
+
+func TestExpressionFileNames(t *testing.T) {
+	expr, err := parseExpression("mit OR apache-2.0 OR gpl-2.0 OR gpl-3.0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	got := expressionFileNames(expr.licenses())
+	expected := []string{"LICENSE-MIT", "LICENSE-APACHE", "LICENSE-GPL-2.0", "LICENSE-GPL-3.0"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v to eq %v", got, expected)
+	}
+}
+
+func TestSPDXExpression(t *testing.T) {
+	bundled := newBundledSource()
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"mit OR apache-2.0", "MIT OR Apache-2.0"},
+		{"gpl-3.0", "GPL-3.0-only"},
+		{"gpl-2.0+ WITH classpath-exception-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0"},
+		{"lgpl-2.1 OR lgpl-3.0+", "LGPL-2.1-only OR LGPL-3.0-or-later"},
+		{"agpl-3.0 AND mpl-2.0", "AGPL-3.0-only AND MPL-2.0"},
+	}
+
+	for _, tc := range cases {
+		expr, err := parseExpression(tc.input)
+		if err != nil {
+			t.Fatalf("%q: err: %s", tc.input, err)
+		}
+
+		var licenses, exceptions []*License
+		for _, r := range expr.licenses() {
+			l, err := bundled.Get(r.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			licenses = append(licenses, l)
+
+			var e *License
+			if r.exception != "" {
+				if e, err = newBundledExceptions().Get(r.exception); err != nil {
+					t.Fatal(err)
+				}
+			}
+			exceptions = append(exceptions, e)
+		}
+
+		got, err := spdxExpression(expr, licenses, exceptions)
+		if err != nil {
+			t.Fatalf("%q: err: %s", tc.input, err)
+		}
+		if got != tc.expected {
+			t.Errorf("expected %q to eq %q", got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitValue is the value of -year and -author options to take them
+// from git history of the repository in current directory.
+const GitValue = "git"
+
+// gitHistory is summary of commits in git repository.
+type gitHistory struct {
+	firstYear int
+	lastYear  int
+
+	// contributors are commit authors in order of their first commit.
+	contributors []string
+}
+
+// years returns years of history, e.g., "2015-2020". It's a single
+// year if all commits are in the same year.
+func (h *gitHistory) years() string {
+	if h.firstYear == h.lastYear {
+		return strconv.Itoa(h.firstYear)
+	}
+	return fmt.Sprintf("%d-%d", h.firstYear, h.lastYear)
+}
+
+// parseGitLog parses commit time (unix time) and author name separated
+// by tab in each line (the same as `git log --format=%at%x09%aN`).
+func parseGitLog(r io.Reader) (*gitHistory, error) {
+	var h gitHistory
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid git log: %q", line)
+		}
+
+		sec, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit time: %q", fields[0])
+		}
+
+		year := time.Unix(sec, 0).Year()
+		if h.firstYear == 0 || year < h.firstYear {
+			h.firstYear = year
+		}
+		if year > h.lastYear {
+			h.lastYear = year
+		}
+
+		author := strings.TrimSpace(fields[1])
+		if author != "" && !seen[author] {
+			seen[author] = true
+			h.contributors = append(h.contributors, author)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if h.firstYear == 0 {
+		return nil, fmt.Errorf("no commits found")
+	}
+	return &h, nil
+}
+
+// readGitHistory reads history of local git repository in dir. Objects
+// are read from .git directory directly, so neither git command nor
+// network is needed. Shallow clone is rejected since the first year
+// can't be known from a part of history.
+func readGitHistory(dir string) (*gitHistory, error) {
+	repo, err := openGitRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if repo.isShallow() {
+		return nil, fmt.Errorf("repository is shallow clone, fetch all history by `git fetch --unshallow`")
+	}
+
+	commits, err := repo.commits()
+	if err != nil {
+		return nil, err
+	}
+
+	// Oldest first like `git log --reverse`
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].time < commits[j].time
+	})
+
+	var buf bytes.Buffer
+	for _, c := range commits {
+		fmt.Fprintf(&buf, "%d\t%s\n", c.time, c.author)
+	}
+
+	Debugf("Read git history in %s", repo.gitDir)
+	return parseGitLog(&buf)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGitLog(t *testing.T) {
+	commit := func(year int, author string) string {
+		return strings.Join([]string{
+			// Middle of the year not to depend on timezone
+			strconv.FormatInt(time.Date(year, 6, 1, 0, 0, 0, 0, time.UTC).Unix(), 10),
+			author,
+		}, "\t")
+	}
+
+	log := strings.Join([]string{
+		commit(2015, "tcnksm"),
+		commit(2016, "Jane Doe"),
+		commit(2016, "tcnksm"),
+		commit(2020, "John Smith"),
+	}, "\n")
+
+	h, err := parseGitLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if got, expected := h.years(), "2015-2020"; got != expected {
+		t.Errorf("expected %q to eq %q", got, expected)
+	}
+
+	expected := []string{"tcnksm", "Jane Doe", "John Smith"}
+	if !reflect.DeepEqual(h.contributors, expected) {
+		t.Errorf("expected %v to eq %v", h.contributors, expected)
+	}
+
+	h, err = parseGitLog(strings.NewReader(commit(2015, "tcnksm")))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got, expected := h.years(), "2015"; got != expected {
+		t.Errorf("expected %q to eq %q", got, expected)
+	}
+
+	if _, err := parseGitLog(strings.NewReader("")); err == nil {
+		t.Fatalf("expect to fail with empty history")
+	}
+}
+
+func TestReadGitHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git command is not found")
+	}
+
+	dir := t.TempDir()
+	git := func(env []string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	git(nil, "init", "-q")
+	for i, c := range []struct {
+		year   int
+		author string
+	}{
+		{2015, "tcnksm"},
+		{2018, "Jane Doe"},
+		{2020, "tcnksm"},
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, "README"), []byte(strconv.Itoa(i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		date := time.Date(c.year, 6, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+		env := []string{
+			"GIT_AUTHOR_NAME=" + c.author, "GIT_AUTHOR_EMAIL=a@example.com", "GIT_AUTHOR_DATE=" + date,
+			"GIT_COMMITTER_NAME=" + c.author, "GIT_COMMITTER_EMAIL=a@example.com", "GIT_COMMITTER_DATE=" + date,
+		}
+		git(env, "add", "README")
+		git(env, "commit", "-q", "-m", "commit")
+	}
+
+	// Loose objects, then pack files
+	for _, gc := range []bool{false, true} {
+		if gc {
+			git(nil, "gc", "-q")
+		}
+
+		h, err := readGitHistory(dir)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got, expected := h.years(), "2015-2020"; got != expected {
+			t.Errorf("expected %q to eq %q", got, expected)
+		}
+		expected := []string{"tcnksm", "Jane Doe"}
+		if !reflect.DeepEqual(h.contributors, expected) {
+			t.Errorf("expected %v to eq %v", h.contributors, expected)
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, ".git", "shallow"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readGitHistory(dir); err == nil {
+		t.Errorf("expect to fail in shallow clone")
+	}
+}
@@ -4,45 +4,133 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/google/go-github/github"
 )
 
-func fetchLicenseList() ([]*github.License, error) {
-	// Create default client
-	client := github.NewClient(nil)
+// githubSource is LicenseSource which fetches LICENSE from GitHub API.
+type githubSource struct {
+	client *github.Client
+}
+
+// newGitHubSource returns githubSource. If baseURL is not empty, it is
+// used as GitHub API endpoint (e.g., GitHub Enterprise). If token is
+// not empty, requests are authenticated by it.
+func newGitHubSource(baseURL, token string) (*githubSource, error) {
+	var httpClient *http.Client
+	if token != "" {
+		Debugf("Use GitHub API token")
+		httpClient = &http.Client{
+			Transport: &tokenTransport{
+				token: token,
+				base:  http.DefaultTransport,
+			},
+		}
+	}
 
-	// Fetch list of LICENSE from Github API
-	list, res, err := client.Licenses.List(context.Background())
+	if baseURL == "" {
+		return &githubSource{
+			client: github.NewClient(httpClient),
+		}, nil
+	}
+
+	Debugf("Use GitHub API: %s", baseURL)
+	client, err := github.NewEnterpriseClient(baseURL, baseURL, httpClient)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid GitHub API URL %q: %s", baseURL, err.Error())
+	}
+
+	return &githubSource{
+		client: client,
+	}, nil
+}
+
+// tokenTransport is http.RoundTripper which authenticates
+// requests to GitHub API by token.
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+// RoundTrip sets token in Authorization header.
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper should not modify the original request
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "token "+t.token)
+	return t.base.RoundTrip(r)
+}
+
+// githubError converts rate limit errors of GitHub API
+// to the messages which tell user when it's reset.
+func githubError(err error) error {
+	switch e := err.(type) {
+	case *github.RateLimitError:
+		return fmt.Errorf("GitHub API rate limit (%d requests/hour) exceeded, it will be reset at %s.\n"+
+			"Set GITHUB_TOKEN or -token option to raise the limit",
+			e.Rate.Limit, e.Rate.Reset.Local().Format(time.RFC1123))
+	case *github.AbuseRateLimitError:
+		if e.RetryAfter != nil {
+			return fmt.Errorf("GitHub API abuse detection triggered, retry after %s", e.GetRetryAfter())
+		}
+		return fmt.Errorf("GitHub API abuse detection triggered, retry later")
+	}
+	return err
+}
+
+// List fetches list of LICENSE from GitHub API.
+// Body of each License is empty.
+func (s *githubSource) List() ([]*License, error) {
+	Debugf("Fetch license list from GitHub API")
+	list, res, err := s.client.Licenses.List(context.Background())
+	if err != nil {
+		return nil, githubError(err)
 	}
 
 	if res.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("invalid status code from GitHub\n %s\n", res.String())
 	}
 
-	return list, nil
+	licenses := make([]*License, 0, len(list))
+	for _, l := range list {
+		licenses = append(licenses, newLicenseFromGitHub(l))
+	}
+
+	return licenses, nil
 }
 
-// fetchLicense fetches LICENSE file from Github API.
+// Get fetches LICENSE file from GitHub API.
 // if something wrong returns error.
-func fetchLicense(key string) (string, error) {
-
-	// Create default client
-	client := github.NewClient(nil)
-
-	// Fetch a LICENSE from Github API
+func (s *githubSource) Get(key string) (*License, error) {
 	Debugf("Fetch license from GitHub API by key: %s", key)
-	license, res, err := client.Licenses.Get(context.Background(), key)
+	license, res, err := s.client.Licenses.Get(context.Background(), key)
 	if err != nil {
-		return "", err
+		if e, ok := err.(*github.ErrorResponse); ok && e.Response != nil && e.Response.StatusCode == http.StatusNotFound {
+			return nil, newNotFoundError("license %q is not found in GitHub API", key)
+		}
+		return nil, githubError(err)
 	}
 
 	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("invalid status code from GitHub\n %s\n", res.String())
+		return nil, fmt.Errorf("invalid status code from GitHub\n %s\n", res.String())
 	}
-	Debugf("Fetched license name: %s", *license.Name)
+	Debugf("Fetched license name: %s", license.GetName())
 
-	return *license.Body, nil
+	return newLicenseFromGitHub(license), nil
+}
+
+// newLicenseFromGitHub converts github.License to License.
+func newLicenseFromGitHub(l *github.License) *License {
+	return &License{
+		Key:            l.GetKey(),
+		Name:           l.GetName(),
+		SPDXID:         l.GetSPDXID(),
+		Description:    l.GetDescription(),
+		Implementation: l.GetImplementation(),
+		Featured:       l.GetFeatured(),
+		Permissions:    l.GetPermissions(),
+		Conditions:     l.GetConditions(),
+		Limitations:    l.GetLimitations(),
+		Body:           l.GetBody(),
+	}
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitHubSource(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token secret" {
+			t.Errorf("expected %q to eq %q", got, "token secret")
+		}
+
+		switch r.URL.Path {
+		case "/api/v3/licenses/mit":
+			fmt.Fprint(w, `{"key": "mit", "name": "MIT License", "spdx_id": "MIT", "body": "MIT"}`)
+		default:
+			// Response when rate limit is exceeded
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "API rate limit exceeded for 127.0.0.1."}`)
+		}
+	}))
+	defer ts.Close()
+
+	s, err := newGitHubSource(ts.URL+"/api/v3/", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := s.Get("mit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Key != "mit" || l.SPDXID != "MIT" || l.Body != "MIT" {
+		t.Errorf("unexpected license: %#v", l)
+	}
+
+	_, err = s.List()
+	if err == nil {
+		t.Fatal("expected error to occur")
+	}
+
+	expected := "rate limit (5000 requests/hour) exceeded, it will be reset at"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected %q to contain %q", err.Error(), expected)
+	}
+}
@@ -0,0 +1,536 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gitRepo reads objects of local git repository directly from .git
+// directory. It supports only what's needed to read commit history:
+// refs, loose objects and pack files (version 2 index).
+type gitRepo struct {
+	// gitDir is .git directory. commonDir is the directory which has
+	// objects and refs shared by worktrees. They're the same without
+	// worktree.
+	gitDir    string
+	commonDir string
+
+	packs []*gitPack
+}
+
+// gitCommit is a commit in git history.
+type gitCommit struct {
+	time    int64
+	author  string
+	parents []string
+}
+
+// openGitRepo opens git repository which has dir. Parent directories
+// of dir are also searched like git command.
+func openGitRepo(dir string) (*gitRepo, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		gitDir, err := findGitDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		if gitDir != "" {
+			return newGitRepo(gitDir)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("not a git repository")
+		}
+		dir = parent
+	}
+}
+
+// findGitDir returns .git directory in dir. .git may be a file which
+// has the path to it (e.g., worktree and submodule). It returns an
+// empty string if dir has no .git.
+func findGitDir(dir string) (string, error) {
+	path := filepath.Join(dir, ".git")
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		return path, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	s := strings.TrimSpace(string(b))
+	if !strings.HasPrefix(s, "gitdir:") {
+		return "", fmt.Errorf("invalid .git file: %s", path)
+	}
+
+	gitDir := strings.TrimSpace(strings.TrimPrefix(s, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	return gitDir, nil
+}
+
+// newGitRepo returns gitRepo of gitDir and loads its pack indexes.
+func newGitRepo(gitDir string) (*gitRepo, error) {
+	r := &gitRepo{gitDir: gitDir, commonDir: gitDir}
+
+	if b, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		r.commonDir = strings.TrimSpace(string(b))
+		if !filepath.IsAbs(r.commonDir) {
+			r.commonDir = filepath.Join(gitDir, r.commonDir)
+		}
+	}
+
+	idxFiles, err := filepath.Glob(filepath.Join(r.commonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range idxFiles {
+		p, err := openGitPack(f)
+		if err != nil {
+			return nil, err
+		}
+		r.packs = append(r.packs, p)
+	}
+	return r, nil
+}
+
+// isShallow returns true if the repository is shallow clone,
+// which has only a part of history.
+func (r *gitRepo) isShallow() bool {
+	_, err := os.Stat(filepath.Join(r.commonDir, "shallow"))
+	return err == nil
+}
+
+// resolveRef returns object name (hex SHA-1) of ref, e.g., "HEAD"
+// or "refs/heads/master". Symbolic refs are followed.
+func (r *gitRepo) resolveRef(ref string) (string, error) {
+	for i := 0; i < 10; i++ {
+		value, err := r.readRef(ref)
+		if err != nil {
+			return "", err
+		}
+
+		if !strings.HasPrefix(value, "ref:") {
+			return value, nil
+		}
+		ref = strings.TrimSpace(strings.TrimPrefix(value, "ref:"))
+	}
+	return "", fmt.Errorf("too many levels of symbolic ref")
+}
+
+// readRef reads value of ref from ref file or packed-refs.
+func (r *gitRepo) readRef(ref string) (string, error) {
+	for _, dir := range []string{r.gitDir, r.commonDir} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err == nil {
+			return strings.TrimSpace(string(b)), nil
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[1] == ref {
+				return fields[0], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("ref %s is not found", ref)
+}
+
+// readObject returns type (e.g., "commit") and content of object.
+func (r *gitRepo) readObject(name string) (string, []byte, error) {
+	sha, err := hex.DecodeString(name)
+	if err != nil || len(sha) != 20 {
+		return "", nil, fmt.Errorf("invalid object name %q", name)
+	}
+
+	for _, p := range r.packs {
+		if offset, ok := p.find(sha); ok {
+			return p.readObject(r, offset)
+		}
+	}
+
+	// Loose object is zlib compressed "TYPE SIZE\0CONTENT"
+	path := filepath.Join(r.commonDir, "objects", name[:2], name[2:])
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("object %s is not found", name)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	b, err := inflate(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid object %s: %s", name, err.Error())
+	}
+
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return "", nil, fmt.Errorf("invalid object %s", name)
+	}
+	typ := strings.SplitN(string(b[:i]), " ", 2)[0]
+	return typ, b[i+1:], nil
+}
+
+// commits returns all commits reachable from HEAD.
+func (r *gitRepo) commits() ([]*gitCommit, error) {
+	head, err := r.resolveRef("HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("no commits found")
+	}
+
+	var commits []*gitCommit
+	seen := map[string]bool{head: true}
+	queue := []string{head}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		typ, b, err := r.readObject(name)
+		if err != nil {
+			return nil, err
+		}
+		if typ != "commit" {
+			return nil, fmt.Errorf("object %s is not a commit but %s", name, typ)
+		}
+
+		c, err := parseGitCommit(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit %s: %s", name, err.Error())
+		}
+		commits = append(commits, c)
+
+		for _, p := range c.parents {
+			if !seen[p] {
+				seen[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	return commits, nil
+}
+
+// parseGitCommit parses headers of commit object, parents and author,
+// e.g., "author Taichi Nakashima <nsd22843@gmail.com> 1420070400 +0900".
+func parseGitCommit(b []byte) (*gitCommit, error) {
+	var c gitCommit
+	author := false
+	for _, line := range strings.Split(string(b), "\n") {
+		if line == "" {
+			// End of headers
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(line, "parent "):
+			c.parents = append(c.parents, strings.TrimPrefix(line, "parent "))
+		case strings.HasPrefix(line, "author "):
+			line = strings.TrimPrefix(line, "author ")
+			i, j := strings.LastIndex(line, "<"), strings.LastIndex(line, ">")
+			if i < 0 || j < i {
+				return nil, fmt.Errorf("invalid author: %q", line)
+			}
+
+			fields := strings.Fields(line[j+1:])
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("invalid author: %q", line)
+			}
+			sec, err := strconv.ParseInt(fields[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid author time: %q", fields[0])
+			}
+
+			c.author = strings.TrimSpace(line[:i])
+			c.time = sec
+			author = true
+		}
+	}
+
+	if !author {
+		return nil, fmt.Errorf("author is not found")
+	}
+	return &c, nil
+}
+
+// gitPack is pack file and its index (version 2).
+// See https://git-scm.com/docs/pack-format
+type gitPack struct {
+	path string
+	idx  []byte
+
+	// n is the number of objects in the pack
+	n uint32
+}
+
+// Types of object in pack file
+const (
+	packCommit   = 1
+	packTree     = 2
+	packBlob     = 3
+	packTag      = 4
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+// packTypes are names of object types in pack file.
+var packTypes = map[byte]string{
+	packCommit: "commit",
+	packTree:   "tree",
+	packBlob:   "blob",
+	packTag:    "tag",
+}
+
+// openGitPack reads pack index in idxPath.
+func openGitPack(idxPath string) (*gitPack, error) {
+	idx, err := ioutil.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header (magic and version), fanout table and trailer
+	if len(idx) < 8+256*4+40 || !bytes.Equal(idx[:8], []byte{0xff, 't', 'O', 'c', 0, 0, 0, 2}) {
+		return nil, fmt.Errorf("unsupported pack index: %s", idxPath)
+	}
+
+	p := &gitPack{
+		path: strings.TrimSuffix(idxPath, ".idx") + ".pack",
+		idx:  idx,
+		n:    binary.BigEndian.Uint32(idx[8+255*4:]),
+	}
+	if len(idx) < 8+256*4+int(p.n)*28 {
+		return nil, fmt.Errorf("invalid pack index: %s", idxPath)
+	}
+	return p, nil
+}
+
+// find returns offset of object sha in pack file.
+func (p *gitPack) find(sha []byte) (int64, bool) {
+	fanout := p.idx[8 : 8+256*4]
+	names := p.idx[8+256*4:]
+
+	lo := 0
+	if sha[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(fanout[(int(sha[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(fanout[int(sha[0])*4:]))
+
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(names[(lo+i)*20:(lo+i+1)*20], sha) >= 0
+	})
+	if i >= hi || !bytes.Equal(names[i*20:(i+1)*20], sha) {
+		return 0, false
+	}
+
+	// Offsets follow names and CRC32 of objects. Offset with MSB is
+	// the index of 8 bytes offset table for large pack file.
+	offsets := names[int(p.n)*24:]
+	offset := binary.BigEndian.Uint32(offsets[i*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true
+	}
+
+	large := offsets[int(p.n)*4:]
+	k := int(offset & 0x7fffffff)
+	if len(large) < (k+1)*8 {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(large[k*8:])), true
+}
+
+// readObject reads object at offset of pack file. Deltified object is
+// resolved with its base object, which may be in another pack (repo).
+func (p *gitPack) readObject(repo *gitRepo, offset int64) (string, []byte, error) {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+
+	// Type and size (which is not needed) in variable length
+	b, err := br.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+	typ := (b >> 4) & 7
+	for b&0x80 != 0 {
+		if b, err = br.ReadByte(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var baseType string
+	var base []byte
+	switch typ {
+	case packOfsDelta:
+		// Base is at the negative offset from this object
+		b, err := br.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+		rel := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = br.ReadByte(); err != nil {
+				return "", nil, err
+			}
+			rel = (rel+1)<<7 | int64(b&0x7f)
+		}
+
+		baseType, base, err = p.readObject(repo, offset-rel)
+		if err != nil {
+			return "", nil, err
+		}
+	case packRefDelta:
+		sha := make([]byte, 20)
+		if _, err := io.ReadFull(br, sha); err != nil {
+			return "", nil, err
+		}
+
+		baseType, base, err = repo.readObject(hex.EncodeToString(sha))
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	data, err := inflate(br)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid object in %s: %s", p.path, err.Error())
+	}
+
+	if base != nil {
+		data, err = applyDelta(base, data)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid delta in %s: %s", p.path, err.Error())
+		}
+		return baseType, data, nil
+	}
+
+	name, ok := packTypes[typ]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown object type %d in %s", typ, p.path)
+	}
+	return name, data, nil
+}
+
+// applyDelta applies delta of pack file to base. Delta has sizes of
+// base and result, then instructions to copy from base or insert data.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	pos := 0
+	size := func() (int, error) {
+		n, shift := 0, uint(0)
+		for {
+			if pos >= len(delta) {
+				return 0, fmt.Errorf("unexpected end of delta")
+			}
+			b := delta[pos]
+			pos++
+			n |= int(b&0x7f) << shift
+			shift += 7
+			if b&0x80 == 0 {
+				return n, nil
+			}
+		}
+	}
+
+	baseSize, err := size()
+	if err != nil {
+		return nil, err
+	}
+	if baseSize != len(base) {
+		return nil, fmt.Errorf("base size mismatch")
+	}
+
+	resultSize, err := size()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, resultSize)
+	for pos < len(delta) {
+		op := delta[pos]
+		pos++
+
+		if op&0x80 == 0 {
+			// Insert the following op bytes
+			n := int(op)
+			if n == 0 || pos+n > len(delta) {
+				return nil, fmt.Errorf("invalid insert instruction")
+			}
+			result = append(result, delta[pos:pos+n]...)
+			pos += n
+			continue
+		}
+
+		// Copy from base. Bits of op tell which bytes of offset
+		// (4 bytes) and size (3 bytes) follow.
+		var offset, n int
+		for i := uint(0); i < 7; i++ {
+			if op&(1<<i) == 0 {
+				continue
+			}
+			if pos >= len(delta) {
+				return nil, fmt.Errorf("unexpected end of delta")
+			}
+			if i < 4 {
+				offset |= int(delta[pos]) << (8 * i)
+			} else {
+				n |= int(delta[pos]) << (8 * (i - 4))
+			}
+			pos++
+		}
+		if n == 0 {
+			n = 0x10000
+		}
+		if offset+n > len(base) {
+			return nil, fmt.Errorf("invalid copy instruction")
+		}
+		result = append(result, base[offset:offset+n]...)
+	}
+
+	if len(result) != resultSize {
+		return nil, fmt.Errorf("result size mismatch")
+	}
+	return result, nil
+}
+
+// inflate reads zlib compressed data from r.
+func inflate(r io.Reader) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return ioutil.ReadAll(zr)
+}
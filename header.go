@@ -0,0 +1,441 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// HeaderScanLines is the number of lines from the top of file
+	// which are scanned to find existing license header.
+	HeaderScanLines = 30
+)
+
+// headerTemplates are license notices which are recommended to put
+// at the top of every source file in the "How to apply" section of
+// each LICENSE. Key of the map is LICENSE key.
+var headerTemplates = map[string]string{
+	"apache-2.0": `Copyright [yyyy] [name of copyright owner]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+`,
+
+	"gpl-2.0": `<one line to give the program's name and a brief idea of what it does.>
+Copyright (C) <year>  <name of author>
+
+This program is free software; you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation; either version 2 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License along
+with this program; if not, write to the Free Software Foundation, Inc.,
+51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+`,
+
+	"gpl-3.0": `<one line to give the program's name and a brief idea of what it does.>
+Copyright (C) <year>  <name of author>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+`,
+
+	"agpl-3.0": `<one line to give the program's name and a brief idea of what it does.>
+Copyright (C) <year>  <name of author>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+`,
+
+	"lgpl-2.1": `<one line to give the library's name and a brief idea of what it does.>
+Copyright (C) <year>  <name of author>
+
+This library is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 2.1 of the License, or (at your option) any later version.
+
+This library is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public
+License along with this library; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA
+`,
+
+	"lgpl-3.0": `<one line to give the program's name and a brief idea of what it does.>
+Copyright (C) <year>  <name of author>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+`,
+
+	"mpl-2.0": `This Source Code Form is subject to the terms of the Mozilla Public
+License, v. 2.0. If a copy of the MPL was not distributed with this
+file, You can obtain one at https://mozilla.org/MPL/2.0/.
+`,
+}
+
+// licenseHeader returns license header template for LICENSE.
+// If short is true or there is no notice for it, the header is
+// the copyright line and SPDX-License-Identifier.
+func licenseHeader(license *License, short bool) (string, error) {
+	if tmpl, ok := headerTemplates[license.Key]; ok && !short {
+		return tmpl, nil
+	}
+
+	if license.SPDXID == "" {
+		return "", fmt.Errorf("SPDX ID of %q is unknown", license.Key)
+	}
+
+	return fmt.Sprintf("Copyright (c) [year] [fullname]\n\n%s %s\n", SPDXTag, currentSPDXID(license.SPDXID, false)), nil
+}
+
+// SPDXTag is the tag of license identifier in source file.
+// See https://spdx.dev/ids/
+const SPDXTag = "SPDX-License-Identifier:"
+
+// spdxTagReg matches SPDX-License-Identifier line and its value.
+var spdxTagReg = regexp.MustCompile(`SPDX-License-Identifier:[ \t]*([^\r\n]*?)[ \t]*(\*/|-->)?[ \t]*$`)
+
+// generatedReg matches the comment of generated file.
+// See https://golang.org/s/generatedcode
+var generatedReg = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// headerAction is what is done to the source file.
+type headerAction int
+
+const (
+	headerSkipped headerAction = iota
+	headerInserted
+	headerUpdated
+)
+
+// headLines returns the first n lines of content.
+func headLines(content []byte, n int) []string {
+	lines := strings.SplitN(string(content), "\n", n+1)
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return lines
+}
+
+// findSPDXID returns SPDX-License-Identifier value in the header of content.
+func findSPDXID(content []byte) (string, bool) {
+	for _, l := range headLines(content, HeaderScanLines) {
+		if m := spdxTagReg.FindStringSubmatch(l); m != nil {
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
+// hasHeader returns true if content already carries license header
+// (copyright line or SPDX-License-Identifier) at the top.
+func hasHeader(content []byte) bool {
+	if _, ok := findSPDXID(content); ok {
+		return true
+	}
+
+	for _, l := range headLines(content, HeaderScanLines) {
+		if strings.Contains(strings.ToLower(l), "copyright") {
+			return true
+		}
+	}
+	return false
+}
+
+// isPreamble returns true if line must stay at the top of file
+// (e.g., shebang, XML declaration or encoding declaration).
+func isPreamble(line string) bool {
+	for _, p := range []string{"#!", "<?xml", "<?php", "<!DOCTYPE", "<!doctype"} {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+
+	// Python encoding declaration and Ruby magic comments
+	return strings.HasPrefix(line, "#") &&
+		(strings.Contains(line, "coding:") || strings.Contains(line, "coding=") ||
+			strings.Contains(line, "frozen_string_literal:"))
+}
+
+// applyHeader inserts header (already commented) to content. If content
+// already has SPDX-License-Identifier which is different from spdxID,
+// it's updated. If content already has other license header, it's skipped.
+func applyHeader(content []byte, header, spdxID string) ([]byte, headerAction) {
+	if generatedReg.Match(content) {
+		return content, headerSkipped
+	}
+
+	if id, ok := findSPDXID(content); ok {
+		if spdxID == "" || sameSPDXID(id, spdxID) {
+			return content, headerSkipped
+		}
+
+		lines := strings.Split(string(content), "\n")
+		for i, l := range lines {
+			if i >= HeaderScanLines {
+				break
+			}
+			if m := spdxTagReg.FindStringSubmatchIndex(l); m != nil {
+				lines[i] = l[:m[2]] + spdxID + l[m[3]:]
+				break
+			}
+		}
+		return []byte(strings.Join(lines, "\n")), headerUpdated
+	}
+
+	if hasHeader(content) {
+		return content, headerSkipped
+	}
+
+	// Keep preamble lines at the top
+	var buf bytes.Buffer
+	rest := string(content)
+	for {
+		i := strings.Index(rest, "\n")
+		if i < 0 || !isPreamble(rest[:i]) {
+			break
+		}
+		buf.WriteString(rest[:i+1])
+		rest = rest[i+1:]
+	}
+
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString(header)
+	if len(rest) > 0 {
+		buf.WriteString("\n")
+		buf.WriteString(strings.TrimLeft(rest, "\n"))
+	}
+
+	return buf.Bytes(), headerInserted
+}
+
+// skipDirs are directories which are not walked in addition to
+// vendorDirs (third-party packages have their own LICENSE).
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"testdata":     true,
+}
+
+// walkSourceFiles walks source files (which comment syntax is known)
+// in paths. Hidden directories, vendorDirs and skipDirs are not walked.
+func walkSourceFiles(paths []string, fn func(path string, style commentStyle) error) error {
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				name := info.Name()
+				if path != root && (contains(vendorDirs, name) || skipDirs[name] || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			style, ok := commentStyleFor(path)
+			if !ok {
+				return nil
+			}
+
+			return fn(path, style)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runHeader runs `license header` command. It inserts license header
+// to source files.
+func (cli *CLI) runHeader(args []string) int {
+	var (
+		key    string
+		short  bool
+		dryRun bool
+		o      options
+	)
+
+	flags := flag.NewFlagSet(Name+" header", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, headerHelpText)
+	}
+
+	flags.StringVar(&key, "key", "", "")
+	flags.BoolVar(&short, "short", false, "")
+	flags.BoolVar(&dryRun, "dry-run", false, "")
+	o.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	if key == "" {
+		key = o.config.License
+	}
+
+	if key == "" {
+		fmt.Fprintf(cli.errStream, "LICENSE key is required: use -key option or license in config file\n")
+		return ExitCodeError
+	}
+	key = strings.ToLower(key)
+
+	license, _, err := cli.getLicense(key, &o)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to get LICENSE: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	header, err := licenseHeader(license, short)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to create license header: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	header, unresolved := cli.ReplacePlaceholders(header, key, o.placeholderOptions())
+	if len(unresolved) > 0 {
+		cli.printUnresolved(unresolved)
+		return ExitCodeError
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	var inserted, updated, skipped int
+	err = walkSourceFiles(paths, func(path string, style commentStyle) error {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		newContent, action := applyHeader(content, style.comment(header), currentSPDXID(license.SPDXID, false))
+		switch action {
+		case headerSkipped:
+			Debugf("Skip %s: it already has license header", path)
+			skipped++
+			return nil
+		case headerInserted:
+			fmt.Fprintf(cli.errStream, "----> Insert license header to %s\n", path)
+			inserted++
+		case headerUpdated:
+			fmt.Fprintf(cli.errStream, "----> Update %s in %s\n", SPDXTag, path)
+			updated++
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, newContent, info.Mode())
+	})
+
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to insert license header: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	msg := fmt.Sprintf("====> Inserted license header to %d files, updated %d files (%d files skipped)", inserted, updated, skipped)
+	if dryRun {
+		msg += " (dry-run)"
+	}
+	fmt.Fprintln(cli.errStream, msg)
+
+	return ExitCodeOK
+}
+
+var headerHelpText = `Usage: license header [option] [PATH...]
+
+  Insert license header to the top of every source file in PATH
+  (by default, current directory). Comment syntax is detected by file
+  extension. Files which already carry license header are skipped, but
+  SPDX-License-Identifier which is different from KEY is updated.
+  Placeholders in the header are replaced like generating LICENSE.
+
+Options:
+
+  -key=KEY            LICENSE key of the header. By default, license
+                      in config file is used.
+
+  -short              Insert only copyright line and SPDX-License-Identifier
+                      instead of the notice recommended by the LICENSE.
+
+  -dry-run            Show files to be changed without changing them.
+
+  Options to replace placeholders (e.g., -author, -year) and to fetch
+  LICENSE (e.g., -offline) are the same as generating LICENSE.
+`
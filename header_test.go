@@ -0,0 +1,166 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyHeader(t *testing.T) {
+	header := slashStyle.comment("Copyright (c) 2015 tcnksm\n\nSPDX-License-Identifier: MIT\n")
+
+	cases := []struct {
+		content  string
+		spdxID   string
+		expected string
+		action   headerAction
+	}{
+		{
+			content:  "package main\n",
+			spdxID:   "MIT",
+			expected: "// Copyright (c) 2015 tcnksm\n//\n// SPDX-License-Identifier: MIT\n\npackage main\n",
+			action:   headerInserted,
+		},
+		{
+			content:  "#!/bin/bash\necho\n",
+			spdxID:   "MIT",
+			expected: "#!/bin/bash\n\n// Copyright (c) 2015 tcnksm\n//\n// SPDX-License-Identifier: MIT\n\necho\n",
+			action:   headerInserted,
+		},
+		{
+			content:  "// Copyright 2015 Google Inc.\n\npackage main\n",
+			spdxID:   "MIT",
+			expected: "// Copyright 2015 Google Inc.\n\npackage main\n",
+			action:   headerSkipped,
+		},
+		{
+			content:  "/* SPDX-License-Identifier: GPL-2.0 */\nint main;\n",
+			spdxID:   "MIT",
+			expected: "/* SPDX-License-Identifier: MIT */\nint main;\n",
+			action:   headerUpdated,
+		},
+		{
+			content:  "// Code generated by stringer; DO NOT EDIT.\n\npackage main\n",
+			spdxID:   "MIT",
+			expected: "// Code generated by stringer; DO NOT EDIT.\n\npackage main\n",
+			action:   headerSkipped,
+		},
+	}
+
+	for i, tc := range cases {
+		got, action := applyHeader([]byte(tc.content), header, tc.spdxID)
+		if string(got) != tc.expected {
+			t.Errorf("#%d expected %q to eq %q", i, string(got), tc.expected)
+		}
+		if action != tc.action {
+			t.Errorf("#%d expected %d to eq %d", i, action, tc.action)
+		}
+	}
+}
+
+func TestCheckHeader(t *testing.T) {
+	license := &License{Key: "apache-2.0", SPDXID: "Apache-2.0"}
+
+	cases := []struct {
+		content string
+		status  string
+		found   string
+	}{
+		{
+			content: "// SPDX-License-Identifier: Apache-2.0\n\npackage main\n",
+		},
+		{
+			content: slashStyle.comment(headerTemplates["apache-2.0"]) + "\npackage main\n",
+		},
+		{
+			content: "package main\n",
+			status:  HeaderMissing,
+		},
+		{
+			content: "# SPDX-License-Identifier: MIT\n",
+			status:  HeaderMismatch,
+			found:   "MIT",
+		},
+		{
+			content: cStyle.comment(headerTemplates["gpl-3.0"]) + "\nint main;\n",
+			status:  HeaderMismatch,
+			found:   "GPL-3.0-only",
+		},
+	}
+
+	for i, tc := range cases {
+		issue := checkHeader("main.go", []byte(tc.content), license)
+		if tc.status == "" {
+			if issue != nil {
+				t.Errorf("#%d expected no issue, got %#v", i, issue)
+			}
+			continue
+		}
+
+		if issue == nil {
+			t.Errorf("#%d expected %q issue", i, tc.status)
+			continue
+		}
+		if issue.Status != tc.status || issue.Found != tc.found {
+			t.Errorf("#%d expected %q (%q), got %q (%q)", i, tc.status, tc.found, issue.Status, issue.Found)
+		}
+	}
+
+	// Deprecated SPDX ID of LICENSE is the same as "-only" and "-or-later"
+	gpl := &License{Key: "gpl-3.0", SPDXID: "GPL-3.0"}
+	for _, id := range []string{"GPL-3.0", "GPL-3.0-only", "GPL-3.0-or-later", "GPL-3.0+"} {
+		if issue := checkHeader("main.go", []byte("// SPDX-License-Identifier: "+id+"\n"), gpl); issue != nil {
+			t.Errorf("expected %s to match GPL-3.0, got %#v", id, issue)
+		}
+	}
+	if issue := checkHeader("main.go", []byte("// SPDX-License-Identifier: GPL-2.0-only\n"), gpl); issue == nil {
+		t.Errorf("expected GPL-2.0-only not to match GPL-3.0")
+	}
+
+	header, err := licenseHeader(gpl, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(header, SPDXTag+" GPL-3.0-only") {
+		t.Errorf("expected %q to contain %q", header, SPDXTag+" GPL-3.0-only")
+	}
+}
+
+func TestWalkSourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"main.go",
+		"cmd/run.go",
+		"vendor/github.com/foo/bar/bar.go",
+		"third_party/foo/foo.go",
+		"node_modules/foo/index.js",
+		"testdata/test.go",
+		".git/hooks/hook.sh",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var paths []string
+	err := walkSourceFiles([]string{dir}, func(path string, style commentStyle) error {
+		rel, _ := filepath.Rel(dir, path)
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"cmd/run.go", "main.go"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %q to eq %q", paths, expected)
+	}
+}
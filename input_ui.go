@@ -2,19 +2,34 @@ package main
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/mitchellh/colorstring"
+	"github.com/tcnksm/go-gitconfig"
 )
 
-// AskNumber asks user to choose number from 1 to max
+// isTerminal returns true if f is terminal (character device).
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// AskNumber asks user to choose number from 1 to max.
+// In non-interactive mode, it returns defaultNum without asking.
 func (cli CLI) AskNumber(max int, defaultNum int) (int, error) {
 
+	if cli.nonInteractive {
+		fmt.Fprintf(cli.errStream, "Your choice? [default: %d] %d\n", defaultNum, defaultNum)
+		return defaultNum, nil
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt)
 	defer signal.Stop(sigCh)
@@ -65,9 +80,15 @@ func (cli CLI) AskNumber(max int, defaultNum int) (int, error) {
 	}
 }
 
-// AskString asks user to input some string
+// AskString asks user to input some string.
+// In non-interactive mode, it returns defaultStr without asking.
 func (cli CLI) AskString(query string, defaultStr string) (string, error) {
 
+	if cli.nonInteractive {
+		fmt.Fprintf(cli.errStream, "%s [default: %s] %s\n", query, defaultStr, defaultStr)
+		return defaultStr, nil
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt)
 	defer signal.Stop(sigCh)
@@ -100,7 +121,11 @@ func (cli CLI) AskString(query string, defaultStr string) (string, error) {
 	}
 }
 
-func (cli *CLI) ReplacePlaceholder(body string, keys []string, query, defaultReplace, optionValue string) string {
+// ReplacePlaceholder replaces placeholders (keys) in body with optionValue.
+// If optionValue is not provided, it asks user with defaultReplace.
+// It also returns placeholders which are not replaced because
+// there is no value for them in non-interactive mode.
+func (cli *CLI) ReplacePlaceholder(body string, keys []string, query, defaultReplace, optionValue string) (string, []string) {
 	// Repalce name if needed
 	folders := findPlaceholders(body, keys)
 
@@ -113,6 +138,10 @@ func (cli *CLI) ReplacePlaceholder(body string, keys []string, query, defaultRep
 			ans, _ = cli.AskString(query, defaultReplace)
 		}
 
+		if ans == DoNothing && cli.nonInteractive {
+			return body, folders
+		}
+
 		if ans != DoNothing {
 			for _, f := range folders {
 				fmt.Fprintf(cli.errStream, "----> Replace placeholder %q to %q in LICENSE body\n", f, ans)
@@ -121,83 +150,124 @@ func (cli *CLI) ReplacePlaceholder(body string, keys []string, query, defaultRep
 		}
 	}
 
-	return body
+	return body, nil
 }
 
-// Choose shows shows LICENSE description from http://choosealicense.com/
-// And ask user to choose LICENSE. It returns key to fetch LICENSE file.
-// If something is wrong, return error.
-func (cli *CLI) Choose() (string, error) {
-	colorstring.Fprintf(cli.errStream, chooseText)
+// placeholderOptions are values of placeholders provided by options.
+// DefaultValue means the value is not provided.
+type placeholderOptions struct {
+	year        string
+	email       string
+	project     string
+	description string
+
+	// holders are copyright holders. If there are multiple holders,
+	// the line of holder placeholder is repeated for each of them.
+	holders []string
+}
 
-	num, err := cli.AskNumber(4, 1)
-	if err != nil {
-		return "", err
+// resolveYear returns year provided by option. By default,
+// current year is used.
+func resolveYear(year string) string {
+	if year == DefaultValue {
+		return strconv.Itoa(time.Now().Year())
 	}
+	return year
+}
 
-	// If user selects 3, should ask user GPL V2 or V3
-	if num == 3 {
-		var buf bytes.Buffer
-		buf.WriteString("\n")
-		buf.WriteString("Which version do you want?\n")
-		buf.WriteString("  1) V2\n")
-		buf.WriteString("  2) V3\n")
-		fmt.Fprintf(cli.errStream, buf.String())
+// ReplacePlaceholders replaces all known placeholders of LICENSE key
+// in body. Values which are not provided by options are asked to user
+// with defaults (gitconfig). It returns placeholders which are not
+// replaced because there is no value for them in non-interactive mode.
+func (cli *CLI) ReplacePlaceholders(body, key string, opts placeholderOptions) (string, []string) {
 
-		num, err = cli.AskNumber(2, 1)
-		if err != nil {
-			return "", err
-		}
-		num += 4
-	}
-
-	var key string
-	switch num {
-	case 1:
-		key = "mit"
-	case 2:
-		key = "apache-2.0"
-	case 4:
-		key = ""
-	case 5:
-		key = "gpl-2.0"
-	case 6:
-		key = "gpl-3.0"
-	default:
-		// Should not reach here
-		panic("Invalid number")
-	}
-
-	return key, nil
-}
+	year := resolveYear(opts.year)
 
-var chooseText = `Choose LICENSE like http://choosealicense.com/
+	author := DefaultValue
+	switch {
+	case len(opts.holders) == 1:
+		author = opts.holders[0]
+	case len(opts.holders) > 1:
+		body = cli.expandHolders(body, placeholderKeys(key, FieldHolder), opts.holders)
+	}
 
-  [blue]Choosing an OSS license doesn't need to be scary[reset]
+	defaultAuthor, _ := gitconfig.GithubUser()
+	if len(defaultAuthor) == 0 {
+		defaultAuthor = DoNothing
+	}
+
+	defaultEmail, _ := gitconfig.Email()
+	if len(defaultEmail) == 0 {
+		defaultEmail = DoNothing
+	}
 
-Which of the following best describes your situation?
+	// Use project name as description by default
+	defaultDescription := opts.project
+	if defaultDescription == DefaultValue {
+		defaultDescription = DoNothing
+	}
 
-  1) I want it simple and permissive.
+	placeholders := []struct {
+		field          Field
+		query          string
+		defaultReplace string
+		optionValue    string
+	}{
+		{FieldYear, "Input year", year, year},
+		{FieldHolder, "Input author name", defaultAuthor, author},
+		{FieldEmail, "Input email", defaultEmail, opts.email},
+		{FieldProject, "Input project name", DoNothing, opts.project},
+		{FieldDescription, "Input one line description of project", defaultDescription, opts.description},
+	}
 
-    The [red][bold]MIT License[reset] is a permissive license that is short and to the
-    point. It lets people do anything they want with your code as long as they
-    provide attribution back to you and don't hold you liable.
-    e.g., jQuery, Rails
+	var unresolved []string
+	for _, p := range placeholders {
+		var u []string
+		keys := placeholderKeys(key, p.field)
+		body, u = cli.ReplacePlaceholder(body, keys, p.query, p.defaultReplace, p.optionValue)
+		unresolved = append(unresolved, u...)
+	}
 
-  2) I'm concerned about patents.
+	return body, unresolved
+}
 
-    The [red][bold]Apache License[reset] (apache-2.0) is a permissive license similar to the MIT License,
-    but also provides an express grant of patent rights from contributors to users.
-    e.g., Apache, SVN, NuGet
+// expandHolders repeats lines which have holder placeholders (keys)
+// for each holder, e.g., copyright line. It keeps the format of
+// the line which differs by LICENSE.
+func (cli *CLI) expandHolders(body string, keys []string, holders []string) string {
+	lines := strings.Split(body, "\n")
+
+	var expanded []string
+	for _, l := range lines {
+		folders := findPlaceholders(l, keys)
+		if len(folders) == 0 {
+			expanded = append(expanded, l)
+			continue
+		}
 
-  3) I care about sharing improvements.
+		for _, h := range holders {
+			line := l
+			for _, f := range folders {
+				line = strings.Replace(line, f, h, -1)
+			}
+			expanded = append(expanded, line)
+		}
+	}
 
-    The [red][bold]GPL V2[reset] (gpl-2.0) or [red][bold]GPL V3[reset] (gpl-3.0) is a copyleft license that requires
-    anyone who distributes your code or a derivative work to make the source available under
-    the same terms. V3 is similar to V2, but further restricts use in hardware that forbids
-    software alterations.
-    e.g., Linux, Git, WordPress
+	quoted := make([]string, 0, len(holders))
+	for _, h := range holders {
+		quoted = append(quoted, strconv.Quote(h))
+	}
+	for _, f := range findPlaceholders(body, keys) {
+		fmt.Fprintf(cli.errStream, "----> Replace placeholder %q to %s in LICENSE body\n", f, strings.Join(quoted, ", "))
+	}
 
-  4) I want more choices.
+	return strings.Join(expanded, "\n")
+}
 
-`
+// printUnresolved tells user placeholders which are not replaced
+// in non-interactive mode and how to provide values for them.
+func (cli *CLI) printUnresolved(unresolved []string) {
+	fmt.Fprintf(cli.errStream, "Failed to resolve placeholders in non-interactive mode: %s\n", strings.Join(unresolved, ", "))
+	fmt.Fprintf(cli.errStream, "Set their values by -author, -email, -project or -description option or config file (or use -raw)\n")
+}
@@ -5,20 +5,81 @@ import (
 	"strings"
 )
 
-var yearKeys = []string{
-	"[year]",
+// Field is the meaning of placeholder in LICENSE body.
+// Placeholders of the same field are replaced with the same value.
+type Field string
+
+const (
+	FieldYear        Field = "year"
+	FieldHolder      Field = "holder"
+	FieldEmail       Field = "email"
+	FieldProject     Field = "project"
+	FieldDescription Field = "description"
+)
+
+// commonPlaceholders are placeholders which can be used in any LICENSE.
+// e.g., GitHub uses [year] and [fullname] in MIT and Apache uses
+// [yyyy] and [name of copyright owner] in its appendix.
+var commonPlaceholders = map[Field][]string{
+	FieldYear: {
+		"[year]",
+		"[yyyy]",
+		"<year>",
+		"{yyyy}",
+		"{year}",
+	},
+	FieldHolder: {
+		"[fullname]",
+		"[name of copyright owner]",
+		"{name of copyright owner}",
+		"<name of author>",
+		"<copyright holders>",
+		"<owner>",
+	},
+	FieldEmail: {
+		"[email]",
+	},
+	FieldProject: {
+		"[project]",
+	},
 }
 
-var nameKeys = []string{
-	"[fullname]",
+// licensePlaceholders are placeholders which are used only in the
+// specific LICENSE. Key of the map is LICENSE key.
+var licensePlaceholders = map[string]map[Field][]string{
+	"agpl-3.0": {
+		FieldDescription: {"<one line to give the program's name and a brief idea of what it does.>"},
+	},
+	"gpl-2.0": {
+		FieldDescription: {"<one line to give the program's name and a brief idea of what it does.>"},
+	},
+	"gpl-3.0": {
+		FieldProject:     {"<program>"},
+		FieldDescription: {"<one line to give the program's name and a brief idea of what it does.>"},
+	},
+	"lgpl-2.1": {
+		FieldDescription: {"<one line to give the library's name and a brief idea of what it does.>"},
+	},
 }
 
-var emailKeys = []string{
-	"[email]",
+// placeholderKeys returns all placeholders of field for LICENSE key.
+func placeholderKeys(key string, field Field) []string {
+	keys := append([]string{}, commonPlaceholders[field]...)
+	return append(keys, licensePlaceholders[key][field]...)
 }
 
-var projectKeys = []string{
-	"[project]",
+// allPlaceholders returns placeholders of every LICENSE.
+func allPlaceholders() []string {
+	var keys []string
+	for _, k := range commonPlaceholders {
+		keys = append(keys, k...)
+	}
+	for _, placeholders := range licensePlaceholders {
+		for _, k := range placeholders {
+			keys = append(keys, k...)
+		}
+	}
+	return keys
 }
 
 func findPlaceholders(body string, keys []string) (folders []string) {
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReplacePlaceholders(t *testing.T) {
+	cli := &CLI{outStream: new(bytes.Buffer), errStream: new(bytes.Buffer), nonInteractive: true}
+	opts := placeholderOptions{
+		year:        "2015",
+		holders:     []string{"Taichi Nakashima"},
+		email:       "nsd22843@gmail.com",
+		project:     "license",
+		description: DefaultValue,
+	}
+
+	body := "Copyright (c) [year] [fullname] <[email]>\n"
+	got, unresolved := cli.ReplacePlaceholders(body, "mit", opts)
+	if len(unresolved) != 0 {
+		t.Fatalf("expected %v to be empty", unresolved)
+	}
+
+	expected := "Copyright (c) 2015 Taichi Nakashima <nsd22843@gmail.com>\n"
+	if got != expected {
+		t.Errorf("expected %q to eq %q", got, expected)
+	}
+
+	// All placeholders in GNU GPL are replaced
+	l, err := newBundledSource().Get("gpl-3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, unresolved = cli.ReplacePlaceholders(l.Body, l.Key, opts)
+	if len(unresolved) != 0 {
+		t.Fatalf("expected %v to be empty", unresolved)
+	}
+
+	for _, s := range []string{"<year>", "<name of author>", "<program>", "<one line to give"} {
+		if strings.Contains(got, s) {
+			t.Errorf("expected %q to be replaced", s)
+		}
+	}
+
+	for _, s := range []string{"Copyright (C) 2015  Taichi Nakashima", "license  Copyright (C) 2015"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("expected body to contain %q", s)
+		}
+	}
+}
+
+func TestReplacePlaceholders_holders(t *testing.T) {
+	cli := &CLI{outStream: new(bytes.Buffer), errStream: new(bytes.Buffer), nonInteractive: true}
+	opts := placeholderOptions{
+		year:        "2015",
+		email:       DefaultValue,
+		project:     DefaultValue,
+		description: DefaultValue,
+		holders:     []string{"Example Inc.", "Taichi Nakashima"},
+	}
+
+	cases := []struct {
+		key      string
+		body     string
+		expected string
+	}{
+		{
+			"mit",
+			"MIT License\n\nCopyright (c) [year] [fullname]\n\nPermission",
+			"MIT License\n\nCopyright (c) 2015 Example Inc.\nCopyright (c) 2015 Taichi Nakashima\n\nPermission",
+		},
+		{
+			"bsd-3-clause",
+			"Copyright (c) [year], [fullname]\nAll rights reserved.\n",
+			"Copyright (c) 2015, Example Inc.\nCopyright (c) 2015, Taichi Nakashima\nAll rights reserved.\n",
+		},
+		{
+			"apache-2.0",
+			"   Copyright [yyyy] [name of copyright owner]\n",
+			"   Copyright 2015 Example Inc.\n   Copyright 2015 Taichi Nakashima\n",
+		},
+	}
+
+	for _, tc := range cases {
+		got, unresolved := cli.ReplacePlaceholders(tc.body, tc.key, opts)
+		if len(unresolved) != 0 {
+			t.Fatalf("expected %v to be empty", unresolved)
+		}
+		if got != tc.expected {
+			t.Errorf("expected %q to eq %q", got, tc.expected)
+		}
+	}
+}
+
+func TestLicensePlaceholders(t *testing.T) {
+	source := newBundledSource()
+	for key, fields := range licensePlaceholders {
+		l, err := source.Get(key)
+		if err != nil {
+			t.Fatalf("%s: should not fail: %s", key, err)
+		}
+
+		for _, placeholders := range fields {
+			for _, p := range placeholders {
+				if !strings.Contains(l.Body, p) {
+					t.Errorf("%s: expected LICENSE to contain %q", key, p)
+				}
+			}
+		}
+	}
+}
@@ -7,6 +7,9 @@ import (
 
 const (
 	EnvDebug = "LI_DEBUG"
+
+	// EnvGitHubToken is environmental variable for GitHub API token
+	EnvGitHubToken = "GITHUB_TOKEN"
 )
 
 func main() {
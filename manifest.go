@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// manifestUpdater updates license field in content of package manifest
+// to SPDX license expression id. The rest of content is kept as it is.
+type manifestUpdater func(content, id string) (string, error)
+
+// errNotManifest is the error when the file is not a package manifest
+// but a config file of tools with the same name, e.g., pyproject.toml
+// which has only [tool.black].
+var errNotManifest = errors.New("not a package manifest")
+
+// manifests are package manifests which have license field. Key is
+// the pattern of the file name.
+var manifests = map[string]manifestUpdater{
+	"package.json":   updateJSONManifest,
+	"composer.json":  updateJSONManifest,
+	"Cargo.toml":     tomlManifestUpdater("package"),
+	"pyproject.toml": tomlManifestUpdater("project", "tool.poetry"),
+	"setup.cfg":      updateSetupCfg,
+	"*.gemspec":      updateGemspec,
+}
+
+// findManifests returns package manifests in dir sorted by path.
+func findManifests(dir string) ([]string, error) {
+	var paths []string
+	for pattern := range manifests {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// updateManifest updates license field of package manifest in path.
+func updateManifest(path, id string) error {
+	var update manifestUpdater
+	for pattern, u := range manifests {
+		if ok, _ := filepath.Match(pattern, filepath.Base(path)); ok {
+			update = u
+		}
+	}
+	if update == nil {
+		return fmt.Errorf("unknown package manifest")
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	updated, err := update(string(content), id)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(updated), info.Mode())
+}
+
+var (
+	// jsonLicenseReg matches "license" field which has string value
+	// at the beginning of text
+	jsonLicenseReg = regexp.MustCompile(`^("license"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+	// jsonFirstFieldReg matches the beginning of the first field
+	// of the top-level object and its indent.
+	jsonFirstFieldReg = regexp.MustCompile(`^\s*\{[ \t]*\n([ \t]*)"`)
+)
+
+// jsonTopLevelField returns the index of field name in the top-level
+// object of JSON content, or -1 if it doesn't exist. Fields of nested
+// objects (e.g., "license" of a dependency) are ignored.
+func jsonTopLevelField(content, name string) int {
+	depth := 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case '"':
+			start := i
+			for i++; i < len(content) && content[i] != '"'; i++ {
+				if content[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(content) {
+				return -1
+			}
+
+			// String followed by ':' is the name of field
+			isField := strings.HasPrefix(strings.TrimLeft(content[i+1:], " \t\r\n"), ":")
+			if depth == 1 && isField && content[start:i+1] == strconv.Quote(name) {
+				return start
+			}
+		}
+	}
+	return -1
+}
+
+// updateJSONManifest updates top-level "license" field of package.json
+// and composer.json. If it doesn't exist, it's added as the first field.
+func updateJSONManifest(content, id string) (string, error) {
+	if i := jsonTopLevelField(content, "license"); i >= 0 {
+		loc := jsonLicenseReg.FindStringSubmatchIndex(content[i:])
+		if loc == nil {
+			return "", fmt.Errorf(`"license" field is not a string`)
+		}
+		return content[:i+loc[3]] + strconv.Quote(id) + content[i+loc[1]:], nil
+	}
+
+	loc := jsonFirstFieldReg.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return "", fmt.Errorf("cannot find where to add \"license\" field")
+	}
+	indent := content[loc[2]:loc[3]]
+	return content[:loc[2]] + indent + `"license": ` + strconv.Quote(id) + ",\n" + content[loc[2]:], nil
+}
+
+// sectionLicenseReg matches license field in TOML and INI (setup.cfg).
+var sectionLicenseReg = regexp.MustCompile(`^(\s*license\s*[=:]\s*)(.*?)\s*$`)
+
+// updateSection updates license field in the first section found in
+// sections (e.g., [package]). value returns the new value from the old
+// one. If the field doesn't exist, it's added at the end of the section.
+func updateSection(content string, sections []string, value func(old string) string) (string, error) {
+	lines := strings.Split(content, "\n")
+
+	start := -1
+	for _, s := range sections {
+		for i, l := range lines {
+			if strings.TrimSpace(l) == "["+s+"]" {
+				start = i
+				break
+			}
+		}
+		if start >= 0 {
+			break
+		}
+	}
+
+	if start < 0 {
+		return "", fmt.Errorf("[%s] section is not found: %w", sections[0], errNotManifest)
+	}
+
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+			end = i
+			break
+		}
+	}
+
+	last := start
+	for i := start + 1; i < end; i++ {
+		if m := sectionLicenseReg.FindStringSubmatch(lines[i]); m != nil {
+			lines[i] = m[1] + value(m[2])
+			return strings.Join(lines, "\n"), nil
+		}
+		if strings.TrimSpace(lines[i]) != "" {
+			last = i
+		}
+	}
+
+	field := "license = " + value("")
+	lines = append(lines[:last+1], append([]string{field}, lines[last+1:]...)...)
+	return strings.Join(lines, "\n"), nil
+}
+
+// tomlTextReg matches text of license table, e.g., {text = "MIT"}
+var tomlTextReg = regexp.MustCompile(`(text\s*=\s*)"[^"]*"`)
+
+// tomlManifestUpdater returns updater of TOML manifest (Cargo.toml and
+// pyproject.toml) which has license field in one of sections.
+func tomlManifestUpdater(sections ...string) manifestUpdater {
+	return func(content, id string) (string, error) {
+		return updateSection(content, sections, func(old string) string {
+			// Keep the table format of old pyproject.toml
+			if strings.HasPrefix(old, "{") && tomlTextReg.MatchString(old) {
+				return tomlTextReg.ReplaceAllString(old, "${1}"+strconv.Quote(id))
+			}
+			return strconv.Quote(id)
+		})
+	}
+}
+
+// updateSetupCfg updates license field in [metadata] of setup.cfg.
+func updateSetupCfg(content, id string) (string, error) {
+	return updateSection(content, []string{"metadata"}, func(string) string {
+		return id
+	})
+}
+
+var (
+	// gemspecLicenseReg matches license (or licenses) attribute of gemspec
+	gemspecLicenseReg = regexp.MustCompile(`(?m)^([ \t]*\w+\.)(licenses?)([ \t]*=[ \t]*).*$`)
+
+	// gemspecVersionReg matches version attribute of gemspec
+	gemspecVersionReg = regexp.MustCompile(`(?m)^([ \t]*\w+\.)version[ \t]*=.*$`)
+)
+
+// gemspecLicenses returns SPDX IDs of licenses attribute of gemspec.
+// Gemspec has a list of licenses which means the choice of them, so
+// only the expression with OR operator can be written.
+func gemspecLicenses(id string) ([]string, error) {
+	var ids []string
+	for _, tok := range exprTokenReg.FindAllString(id, -1) {
+		switch strings.ToUpper(tok) {
+		case "AND", "WITH":
+			return nil, fmt.Errorf("gemspec cannot have SPDX license expression with %s: %s", strings.ToUpper(tok), id)
+		case "OR", "(", ")":
+			continue
+		}
+		ids = append(ids, tok)
+	}
+	return ids, nil
+}
+
+// updateGemspec updates license attribute of *.gemspec. If it doesn't
+// exist, it's added after version attribute. When there are multiple
+// LICENSE (OR expression), licenses attribute is used.
+func updateGemspec(content, id string) (string, error) {
+	ids, err := gemspecLicenses(id)
+	if err != nil {
+		return "", err
+	}
+
+	quoted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		quoted = append(quoted, strconv.Quote(id))
+	}
+
+	attrValue := func(attr string) (string, string) {
+		if len(ids) > 1 {
+			attr = "licenses"
+		}
+		if attr == "licenses" {
+			return attr, "[" + strings.Join(quoted, ", ") + "]"
+		}
+		return attr, quoted[0]
+	}
+
+	if m := gemspecLicenseReg.FindStringSubmatchIndex(content); m != nil {
+		prefix, sep := content[m[2]:m[3]], content[m[6]:m[7]]
+		attr, value := attrValue(content[m[4]:m[5]])
+		return content[:m[0]] + prefix + attr + sep + value + content[m[1]:], nil
+	}
+
+	m := gemspecVersionReg.FindStringSubmatchIndex(content)
+	if m == nil {
+		return "", fmt.Errorf("cannot find where to add license attribute")
+	}
+	prefix := content[m[2]:m[3]]
+	attr, value := attrValue("license")
+	return content[:m[1]] + "\n" + prefix + attr + " = " + value + content[m[1]:], nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUpdateManifest(t *testing.T) {
+	cases := []struct {
+		update   manifestUpdater
+		input    string
+		expected string
+	}{
+		{
+			updateJSONManifest,
+			"{\n  \"name\": \"license\",\n  \"license\": \"ISC\",\n  \"version\": \"1.0.0\"\n}\n",
+			"{\n  \"name\": \"license\",\n  \"license\": \"MIT OR Apache-2.0\",\n  \"version\": \"1.0.0\"\n}\n",
+		},
+		{
+			updateJSONManifest,
+			"{\n    \"name\": \"tcnksm/license\"\n}\n",
+			"{\n    \"license\": \"MIT OR Apache-2.0\",\n    \"name\": \"tcnksm/license\"\n}\n",
+		},
+		{
+			updateJSONManifest,
+			"{\n  \"name\": \"license\",\n  \"author\": {\"license\": \"ISC\"},\n  \"license\": \"ISC\"\n}\n",
+			"{\n  \"name\": \"license\",\n  \"author\": {\"license\": \"ISC\"},\n  \"license\": \"MIT OR Apache-2.0\"\n}\n",
+		},
+		{
+			updateJSONManifest,
+			"{\n  \"name\": \"license\",\n  \"bundled\": [{\"license\": \"ISC\"}]\n}\n",
+			"{\n  \"license\": \"MIT OR Apache-2.0\",\n  \"name\": \"license\",\n  \"bundled\": [{\"license\": \"ISC\"}]\n}\n",
+		},
+		{
+			tomlManifestUpdater("package"),
+			"[package]\nname = \"license\"\nlicense = \"MIT\" \n\n[dependencies]\nfoo = \"1\"\n",
+			"[package]\nname = \"license\"\nlicense = \"MIT OR Apache-2.0\"\n\n[dependencies]\nfoo = \"1\"\n",
+		},
+		{
+			tomlManifestUpdater("package"),
+			"[package]\nname = \"license\"\nversion = \"0.1.0\"\n\n[dependencies]\n",
+			"[package]\nname = \"license\"\nversion = \"0.1.0\"\nlicense = \"MIT OR Apache-2.0\"\n\n[dependencies]\n",
+		},
+		{
+			tomlManifestUpdater("project", "tool.poetry"),
+			"[project]\nname = \"license\"\nlicense = {text = \"MIT\"}\n",
+			"[project]\nname = \"license\"\nlicense = {text = \"MIT OR Apache-2.0\"}\n",
+		},
+		{
+			tomlManifestUpdater("project", "tool.poetry"),
+			"[tool.poetry]\nname = \"license\"\nlicense-file = \"LICENSE\"\n",
+			"[tool.poetry]\nname = \"license\"\nlicense-file = \"LICENSE\"\nlicense = \"MIT OR Apache-2.0\"\n",
+		},
+		{
+			updateSetupCfg,
+			"[metadata]\nname = license\nlicense = MIT\nlicense_files = LICENSE\n",
+			"[metadata]\nname = license\nlicense = MIT OR Apache-2.0\nlicense_files = LICENSE\n",
+		},
+		{
+			updateGemspec,
+			"Gem::Specification.new do |spec|\n  spec.name = \"license\"\n  spec.licenses = [\"MIT\"]\nend\n",
+			"Gem::Specification.new do |spec|\n  spec.name = \"license\"\n  spec.licenses = [\"MIT\", \"Apache-2.0\"]\nend\n",
+		},
+		{
+			updateGemspec,
+			"Gem::Specification.new do |s|\n  s.name    = \"license\"\n  s.version = \"0.1.0\"\nend\n",
+			"Gem::Specification.new do |s|\n  s.name    = \"license\"\n  s.version = \"0.1.0\"\n  s.licenses = [\"MIT\", \"Apache-2.0\"]\nend\n",
+		},
+	}
+
+	for _, tc := range cases {
+		got, err := tc.update(tc.input, "MIT OR Apache-2.0")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got != tc.expected {
+			t.Errorf("expected %q to eq %q", got, tc.expected)
+		}
+	}
+
+	if _, err := tomlManifestUpdater("package")("[workspace]\n", "MIT"); !errors.Is(err, errNotManifest) {
+		t.Errorf("expect to fail without [package] section: %v", err)
+	}
+
+	if _, err := tomlManifestUpdater("project", "tool.poetry")("[tool.black]\nline-length = 88\n", "MIT"); !errors.Is(err, errNotManifest) {
+		t.Errorf("expect pyproject.toml without [project] not to be manifest: %v", err)
+	}
+
+	gemspec := "Gem::Specification.new do |spec|\n  spec.license = \"ISC\"\nend\n"
+	got, err := updateGemspec(gemspec, "MIT")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := "Gem::Specification.new do |spec|\n  spec.license = \"MIT\"\nend\n"
+	if got != expected {
+		t.Errorf("expected %q to eq %q", got, expected)
+	}
+
+	for _, id := range []string{"MIT AND Apache-2.0", "Apache-2.0 WITH LLVM-exception"} {
+		if _, err := updateGemspec(gemspec, id); err == nil {
+			t.Errorf("expect %q to fail for gemspec", id)
+		}
+	}
+}
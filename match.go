@@ -0,0 +1,178 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MatchThreshold is the minimum similarity to regard
+// two texts as the same LICENSE.
+const MatchThreshold = 0.95
+
+// licenseFileNames are file names of LICENSE in the project root.
+var licenseFileNames = []string{
+	"LICENSE",
+	"LICENSE.md",
+	"LICENSE.txt",
+	"LICENCE",
+	"LICENCE.md",
+	"LICENCE.txt",
+	"COPYING",
+	"COPYING.md",
+	"COPYING.txt",
+	"UNLICENSE",
+}
+
+// findLicenseFile finds LICENSE file in dir (case insensitive).
+func findLicenseFile(dir string) (string, bool) {
+	return findFile(dir, licenseFileNames)
+}
+
+// findFile finds file which has one of names in dir (case insensitive).
+// The former name is preferred.
+func findFile(dir string, names []string) (string, bool) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for _, name := range names {
+		for _, f := range files {
+			if !f.IsDir() && strings.EqualFold(f.Name(), name) {
+				return filepath.Join(dir, f.Name()), true
+			}
+		}
+	}
+	return "", false
+}
+
+// copyrightLineReg matches copyright lines, which are different
+// in every project and must be ignored when comparing LICENSE.
+// Copyright may be in the middle of line, e.g., "<program> Copyright
+// (C) <year> <name of author>" of GPL-3.0. "(c)" without "Copyright"
+// must be followed by year, it's also a list marker (e.g., "(c) You
+// must retain..." of Apache-2.0).
+var copyrightLineReg = regexp.MustCompile(`(?im)^(.*copyright\s*(\(c\)|©|\d{4}|[\[<{])|[^a-z0-9\n]*(©|\(c\)\s*(\d{4}|[\[<{]))).*$`)
+
+// listMarkerReg matches markers of list items (e.g., "1.", "(a)", "*").
+var listMarkerReg = regexp.MustCompile(`(?m)^\s*(\d+\.|\(?[a-z0-9]\)|[*-])\s+`)
+
+// nonWordReg matches characters which are not part of words.
+var nonWordReg = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalizeText converts LICENSE text to words for comparison.
+// Placeholders, copyright lines, list markers, case, punctuation and
+// whitespace are ignored.
+func normalizeText(text string) []string {
+	for _, k := range allPlaceholders() {
+		text = strings.Replace(text, k, " ", -1)
+	}
+
+	text = copyrightLineReg.ReplaceAllString(text, " ")
+	text = listMarkerReg.ReplaceAllString(text, " ")
+	text = nonWordReg.ReplaceAllString(strings.ToLower(text), " ")
+	return strings.Fields(text)
+}
+
+// shingles returns the set of word pairs (bigrams). Comparing them
+// is more sensitive to the order of words than comparing words.
+func shingles(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	if len(words) == 1 {
+		set[words[0]] = true
+	}
+	for i := 0; i+1 < len(words); i++ {
+		set[words[i]+" "+words[i+1]] = true
+	}
+	return set
+}
+
+// similarity returns Sørensen–Dice coefficient of two texts (0 to 1).
+func similarity(a, b []string) float64 {
+	sa, sb := shingles(a), shingles(b)
+	if len(sa)+len(sb) == 0 {
+		return 0
+	}
+
+	overlap := 0
+	for s := range sa {
+		if sb[s] {
+			overlap++
+		}
+	}
+	return 2 * float64(overlap) / float64(len(sa)+len(sb))
+}
+
+// containment returns the ratio of part which is contained in whole
+// (0 to 1). It's used to find a notice in longer text.
+func containment(part, whole []string) float64 {
+	sp, sw := shingles(part), shingles(whole)
+	if len(sp) == 0 {
+		return 0
+	}
+
+	overlap := 0
+	for s := range sp {
+		if sw[s] {
+			overlap++
+		}
+	}
+	return float64(overlap) / float64(len(sp))
+}
+
+// Match is the result of matching text against LICENSE corpus.
+type Match struct {
+	License    *License
+	Similarity float64
+}
+
+// matchLicense compares text with each LICENSE in corpus and returns
+// matches sorted by similarity (the best first).
+func matchLicense(text string, corpus []*License) []Match {
+	words := normalizeText(text)
+
+	matches := make([]Match, 0, len(corpus))
+	for _, l := range corpus {
+		matches = append(matches, Match{
+			License:    l,
+			Similarity: similarity(words, normalizeText(l.Body)),
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Similarity > matches[j].Similarity
+	})
+	return matches
+}
+
+// loadCorpus returns all LICENSE in source with body. Bodies are
+// read from cache if available (see CLI.getLicense).
+func (cli *CLI) loadCorpus(o *options) ([]*License, error) {
+	list, err := cli.source.List()
+	if err != nil {
+		return nil, err
+	}
+
+	corpus := make([]*License, 0, len(list))
+	for _, l := range list {
+		license, _, err := cli.getLicense(l.Key, o)
+		if err != nil {
+			Debugf("Failed to get LICENSE %q: %s", l.Key, err.Error())
+			continue
+		}
+
+		// Metadata in the list is preferred to the one of cache
+		if license.Name == license.Key {
+			license.Name = l.Name
+		}
+		if license.SPDXID == "" {
+			license.SPDXID = l.SPDXID
+		}
+		corpus = append(corpus, license)
+	}
+
+	return corpus, nil
+}
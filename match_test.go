@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchLicense(t *testing.T) {
+	source := newBundledSource()
+	list, err := source.List()
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	var corpus []*License
+	for _, l := range list {
+		license, err := source.Get(l.Key)
+		if err != nil {
+			t.Fatalf("should not fail: %s", err)
+		}
+		corpus = append(corpus, license)
+	}
+
+	for _, key := range []string{"mit", "bsd-2-clause", "bsd-3-clause", "gpl-2.0", "gpl-3.0", "apache-2.0"} {
+		license, _ := source.Get(key)
+
+		// Filled placeholders and different line breaks
+		text := strings.Replace(license.Body, "[year]", "2015", -1)
+		text = strings.Replace(text, "[fullname]", "Taichi Nakashima", -1)
+		text = strings.Replace(text, "\n", " \n  ", -1)
+
+		matches := matchLicense(text, corpus)
+		if matches[0].License.Key != key {
+			t.Errorf("expected %q to eq %q", matches[0].License.Key, key)
+		}
+		if matches[0].Similarity < MatchThreshold {
+			t.Errorf("expected %f to be larger than %f", matches[0].Similarity, MatchThreshold)
+		}
+		if matches[1].Similarity >= MatchThreshold {
+			t.Errorf("expected %s (%f) not to match %s", matches[1].License.Key, matches[1].Similarity, key)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// NoticeFileName is the name of NOTICE file generated alongside LICENSE.
+const NoticeFileName = "NOTICE"
+
+// noticeFileNames are file names of NOTICE in vendored packages.
+var noticeFileNames = []string{
+	"NOTICE",
+	"NOTICE.txt",
+	"NOTICE.md",
+}
+
+// noticeTemplate is NOTICE of the project required by Apache License 2.0
+// (Section 4(d)). Placeholders are replaced like LICENSE.
+const noticeTemplate = "[project]\nCopyright [year] [fullname]\n"
+
+// vendoredNotice is NOTICE of vendored package.
+type vendoredNotice struct {
+	name string
+	text []byte
+}
+
+// findVendoredNotices returns NOTICE of packages in vendored directories
+// (see vendorDirs) under root.
+func findVendoredNotices(root string) ([]vendoredNotice, error) {
+	pkgs, err := findVendoredPackages(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var notices []vendoredNotice
+	for _, pkg := range pkgs {
+		file, ok := findFile(pkg.dir, noticeFileNames)
+		if !ok {
+			continue
+		}
+
+		text, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		notices = append(notices, vendoredNotice{name: pkg.name, text: text})
+	}
+	return notices, nil
+}
+
+// noticeText returns NOTICE which has notice of the project followed
+// by NOTICE of vendored packages.
+func noticeText(notice string, vendored []vendoredNotice) string {
+	var buf bytes.Buffer
+	buf.WriteString(notice)
+	if len(vendored) == 0 {
+		return buf.String()
+	}
+
+	separator := strings.Repeat("=", 80)
+	fmt.Fprintf(&buf, "\nThis product includes the following third-party software.\n")
+	for _, v := range vendored {
+		fmt.Fprintf(&buf, "\n%s\n%s\n%s\n", separator, v.name, separator)
+		fmt.Fprintf(&buf, "\n%s\n", strings.TrimSpace(string(v.text)))
+	}
+	return buf.String()
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// options are options shared by generating LICENSE and subcommands.
+type options struct {
+	// Replacement values. DefaultValue means it's not provided.
+	year        string
+	email       string
+	project     string
+	description string
+
+	// holders are copyright holders. -author can be repeated.
+	holders stringsFlag
+
+	noCache        bool
+	offline        bool
+	nonInteractive bool
+	debug          bool
+
+	templatesDir string
+	spdxData     string
+	githubAPI    string
+	githubToken  string
+
+	// Followings are set by CLI.setup
+	config        *Config
+	cacheDir      string
+	cacheDuration time.Duration
+}
+
+// stringsFlag is a flag which can be repeated, e.g., -author=A -author=B.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// register defines flags of options.
+func (o *options) register(flags *flag.FlagSet) {
+	flags.StringVar(&o.year, "year", DefaultValue, "")
+	flags.Var(&o.holders, "author", "")
+	flags.StringVar(&o.email, "email", DefaultValue, "")
+	flags.StringVar(&o.project, "project", DefaultValue, "")
+	flags.StringVar(&o.description, "description", DefaultValue, "")
+
+	flags.BoolVar(&o.noCache, "no-cache", false, "")
+	flags.BoolVar(&o.offline, "offline", false, "")
+	flags.BoolVar(&o.nonInteractive, "non-interactive", false, "")
+	flags.BoolVar(&o.nonInteractive, "yes", false, "")
+	flags.BoolVar(&o.debug, "debug", false, "")
+
+	flags.StringVar(&o.templatesDir, "templates", "", "")
+	flags.StringVar(&o.spdxData, "spdx", "", "")
+	flags.StringVar(&o.githubAPI, "github-api", "", "")
+	flags.StringVar(&o.githubToken, "token", os.Getenv(EnvGitHubToken), "")
+}
+
+// placeholderOptions returns values of placeholders provided by options.
+func (o *options) placeholderOptions() placeholderOptions {
+	return placeholderOptions{
+		year:        o.year,
+		holders:     o.holders,
+		email:       o.email,
+		project:     o.project,
+		description: o.description,
+	}
+}
+
+// setup prepares CLI by parsed options. It enables debug and
+// non-interactive mode, loads config files and creates LICENSE source.
+// Options which are not provided by flags are taken from config.
+// Precedence is flags > repository config > user config > gitconfig.
+func (cli *CLI) setup(flags *flag.FlagSet, o *options) error {
+
+	// Set Debug environmental variable
+	if o.debug {
+		os.Setenv(EnvDebug, "1")
+		Debugf("Run as DEBUG mode")
+	}
+
+	// Never ask user when stdin is not terminal (e.g., on CI)
+	if o.nonInteractive || !isTerminal(os.Stdin) {
+		Debugf("Run as non-interactive mode")
+		cli.nonInteractive = true
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		Debugf("Failed to get home directory: %s", err.Error())
+		o.noCache = true
+		home = "."
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		Debugf("Failed to get current directory: %s", err.Error())
+		wd = "."
+	}
+
+	config, err := loadConfig(home, wd)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %s", err.Error())
+	}
+	o.config = config
+
+	for _, v := range []struct {
+		option *string
+		value  string
+	}{
+		{&o.email, config.Email},
+		{&o.project, config.Project},
+		{&o.description, config.Description},
+	} {
+		if *v.option == DefaultValue && v.value != "" {
+			*v.option = v.value
+		}
+	}
+
+	if len(o.holders) == 0 {
+		if len(config.Holders) > 0 {
+			o.holders = config.Holders
+		} else if config.Author != "" {
+			o.holders = stringsFlag{config.Author}
+		}
+	}
+
+	// Take years and contributors from local git history
+	if o.year == GitValue || contains(o.holders, GitValue) {
+		history, err := readGitHistory(wd)
+		if err != nil {
+			return fmt.Errorf("failed to read git history: %s", err.Error())
+		}
+
+		if o.year == GitValue {
+			o.year = history.years()
+		}
+
+		var holders stringsFlag
+		for _, h := range o.holders {
+			if h == GitValue {
+				holders = append(holders, history.contributors...)
+				continue
+			}
+			holders = append(holders, h)
+		}
+		o.holders = holders
+	}
+
+	if !isFlagSet(flags, "no-cache") && config.NoCache != nil {
+		o.noCache = *config.NoCache
+	}
+
+	o.cacheDir = filepath.Join(home, CacheDirName)
+	if config.CacheDir != "" {
+		o.cacheDir = config.CacheDir
+	}
+
+	o.cacheDuration = CacheDuration
+	if config.CacheDuration != "" {
+		// It's already validated when loading config
+		o.cacheDuration, _ = time.ParseDuration(config.CacheDuration)
+	}
+
+	if o.templatesDir == "" {
+		o.templatesDir = config.Templates
+	}
+
+	if o.templatesDir == "" {
+		o.templatesDir = filepath.Join(home, DefaultTemplatesDir)
+	}
+
+	// Use GitHub API as default LICENSE source and bundled one
+	// as fallback. Custom templates in local directory are preferred
+	// to them. In offline mode, never touch the network.
+	// SPDX License List data is used only when it's provided.
+	if cli.source == nil {
+		Debugf("Custom templates directory: %s", o.templatesDir)
+		sources := chainSource{newDirSource(o.templatesDir)}
+
+		if o.spdxData != "" {
+			spdx := newSPDXSource(o.spdxData)
+			if o.offline && spdx.isRemote() {
+				return fmt.Errorf("cannot use SPDX data from URL in offline mode: %s", o.spdxData)
+			}
+			sources = append(sources, spdx)
+		}
+
+		if o.offline {
+			Debugf("Run as offline mode")
+		} else {
+			gh, err := newGitHubSource(o.githubAPI, o.githubToken)
+			if err != nil {
+				return fmt.Errorf("failed to create GitHub client: %s", err.Error())
+			}
+			sources = append(sources, &warnSource{LicenseSource: gh, name: "GitHub API", errStream: cli.errStream})
+		}
+
+		cli.source = append(sources, newBundledSource())
+	}
+
+	if cli.exceptions == nil {
+		cli.exceptions = chainSource{
+			newDirSource(filepath.Join(o.templatesDir, ExceptionsDirName)),
+			newBundledExceptions(),
+		}
+	}
+
+	return nil
+}
+
+// isFlagSet returns true if the flag is provided in command line.
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// getLicense returns LICENSE by key from the first source which has it.
+// By default, LICENSE of remote source (e.g., GitHub API) is read from
+// local cache if it's available. Otherwise it's fetched and saved in
+// cache. Cache is also read before the bundled LICENSE (e.g., offline).
+// LICENSE of local source (custom templates and bundled one) is never
+// cached, so custom templates always win. It also returns true if cache
+// is used.
+func (cli *CLI) getLicense(key string, o *options) (*License, bool, error) {
+	sources, ok := cli.source.(chainSource)
+	if !ok {
+		sources = chainSource{cli.source}
+	}
+
+	var (
+		firstErr     error
+		cacheChecked bool
+	)
+	for _, s := range sources {
+		remote := isRemoteSource(s)
+
+		// By default noCache is false (useCache) and check cache is exist
+		// or not before the first remote source or the bundled one
+		if !o.noCache && !cacheChecked && (remote || isBundledSource(s)) {
+			cacheChecked = true
+			body, err := getCache(key, o.cacheDir, o.cacheDuration)
+			if err == nil && len(body) != 0 {
+				// Cache has only body, metadata is taken from bundled one if exists
+				license, err := newBundledSource().Get(key)
+				if err != nil {
+					license = &License{Key: key, Name: key}
+				}
+				license.Body = body
+				return license, true, nil
+			}
+
+			if err != nil {
+				Debugf("Failed to get cache: %s", err.Error())
+			}
+		}
+
+		license, err := s.Get(key)
+		if err != nil {
+			Debugf("Failed to get LICENSE %q: %s", key, err.Error())
+			firstErr = preferError(firstErr, err)
+			continue
+		}
+
+		if !o.noCache && remote {
+			if err := setCache(license.Body, key, o.cacheDir); err != nil {
+				Debugf("Failed to save cache: %s", err.Error())
+			}
+		}
+
+		return license, false, nil
+	}
+
+	if firstErr == nil {
+		firstErr = fmt.Errorf("no LICENSE source")
+	}
+	return nil, false, firstErr
+}
+
+// isRemoteSource returns true if LICENSE of s is fetched via network.
+// Only LICENSE of such source is cached.
+func isRemoteSource(s LicenseSource) bool {
+	switch s := s.(type) {
+	case *fsSource:
+		return false
+	case *spdxSource:
+		return s.isRemote()
+	}
+	return true
+}
+
+// isBundledSource returns true if s is the snapshot bundled in the binary.
+func isBundledSource(s LicenseSource) bool {
+	f, ok := s.(*fsSource)
+	return ok && f.bundled
+}
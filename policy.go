@@ -0,0 +1,342 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// PolicyFileNames are file names of policy in the project root.
+var PolicyFileNames = []string{".license-policy.yaml", ".license-policy.yml"}
+
+// vendorDirs are directories which contain third-party packages.
+var vendorDirs = []string{"vendor", "third_party"}
+
+// ProjectPackage is package name of the project itself in policy.
+const ProjectPackage = "."
+
+// Policy is allowed and denied LICENSE keys. If Allow is empty,
+// any LICENSE which is not denied is allowed. LICENSE with exception
+// can be written as "gpl-2.0 WITH classpath-exception-2.0".
+type Policy struct {
+	Allow      []string          `yaml:"allow"`
+	Deny       []string          `yaml:"deny"`
+	Exceptions []PolicyException `yaml:"exceptions"`
+}
+
+// PolicyException allows package to violate policy. If License is empty,
+// any LICENSE of the package is allowed.
+type PolicyException struct {
+	Package       string `yaml:"package"`
+	License       string `yaml:"license"`
+	Justification string `yaml:"justification"`
+}
+
+// readPolicy reads policy file. LICENSE keys are normalized
+// (see normalizePolicyID).
+func readPolicy(path string) (*Policy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Policy
+	if err := yaml.UnmarshalStrict(b, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", path, err.Error())
+	}
+
+	for i := range p.Allow {
+		p.Allow[i] = normalizePolicyID(p.Allow[i])
+	}
+	for i := range p.Deny {
+		p.Deny[i] = normalizePolicyID(p.Deny[i])
+		if contains(p.Allow, p.Deny[i]) {
+			return nil, fmt.Errorf("%q is both allowed and denied in %s", p.Deny[i], path)
+		}
+	}
+
+	for i, e := range p.Exceptions {
+		if e.Package == "" {
+			return nil, fmt.Errorf("package of exception is required in %s", path)
+		}
+		if e.Justification == "" {
+			return nil, fmt.Errorf("justification of exception for %q is required in %s", e.Package, path)
+		}
+		p.Exceptions[i].License = normalizePolicyID(e.License)
+	}
+
+	return &p, nil
+}
+
+// findPolicyFile finds policy file in dir.
+func findPolicyFile(dir string) (string, bool) {
+	for _, name := range PolicyFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// policyWith is the separator of LICENSE and its exception in policy.
+const policyWith = " with "
+
+// normalizePolicyID converts LICENSE in policy to lower case and
+// normalizes whitespace, e.g., "gpl-2.0 with classpath-exception-2.0".
+func normalizePolicyID(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// policyID returns LICENSE key with exception (if any) in policy.
+func policyID(key, exception string) string {
+	if key == "" || exception == "" {
+		return key
+	}
+	return key + policyWith + exception
+}
+
+// policyBase returns LICENSE key of id without exception.
+func policyBase(id string) string {
+	return strings.SplitN(id, policyWith, 2)[0]
+}
+
+// evaluate returns why LICENSE id (see policyID) violates policy. It
+// returns empty string if it's allowed. Empty id means LICENSE is unknown.
+// LICENSE with exception is evaluated by the entry with the exception
+// first, then by the entry of LICENSE itself.
+func (p *Policy) evaluate(id string) string {
+	base := policyBase(id)
+	switch {
+	case id == "":
+		return "LICENSE is unknown"
+	case contains(p.Deny, id):
+		return fmt.Sprintf("%s is denied", id)
+	case contains(p.Allow, id):
+		return ""
+	case contains(p.Deny, base):
+		return fmt.Sprintf("%s is denied", base)
+	case len(p.Allow) > 0 && !contains(p.Allow, base):
+		return fmt.Sprintf("%s is not allowed", id)
+	default:
+		return ""
+	}
+}
+
+// exception returns the exception for LICENSE id (see policyID) of pkg.
+func (p *Policy) exception(pkg, id string) (*PolicyException, bool) {
+	for i, e := range p.Exceptions {
+		if e.Package != pkg {
+			continue
+		}
+		if e.License == "" || e.License == id || e.License == policyBase(id) {
+			return &p.Exceptions[i], true
+		}
+	}
+	return nil, false
+}
+
+// policyPackage is the project or vendored package and its LICENSE.
+type policyPackage struct {
+	name string
+	dir  string
+	key  string
+}
+
+// findVendoredPackages returns directories which have LICENSE file
+// in vendorDirs under root. Package name is the path relative to
+// vendor directory (e.g., github.com/mitchellh/go-homedir).
+func findVendoredPackages(root string) ([]policyPackage, error) {
+	var pkgs []policyPackage
+	for _, v := range vendorDirs {
+		vendor := filepath.Join(root, v)
+		if info, err := os.Stat(vendor); err != nil || !info.IsDir() {
+			continue
+		}
+
+		err := filepath.Walk(vendor, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() || path == vendor {
+				return nil
+			}
+
+			if _, ok := findLicenseFile(path); !ok {
+				return nil
+			}
+
+			rel, err := filepath.Rel(vendor, path)
+			if err != nil {
+				return err
+			}
+			pkgs = append(pkgs, policyPackage{name: filepath.ToSlash(rel), dir: path})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].name < pkgs[j].name
+	})
+	return pkgs, nil
+}
+
+// runPolicy runs `license policy` command.
+func (cli *CLI) runPolicy(args []string) int {
+	if len(args) < 2 || args[1] != "check" {
+		fmt.Fprint(cli.errStream, policyHelpText)
+		return ExitCodeError
+	}
+
+	return cli.runPolicyCheck(args[1:])
+}
+
+// runPolicyCheck runs `license policy check` command. It checks LICENSE
+// of the project and its vendored packages against policy.
+func (cli *CLI) runPolicyCheck(args []string) int {
+	var (
+		policyFile string
+		o          options
+	)
+
+	flags := flag.NewFlagSet(Name+" policy check", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, policyHelpText)
+	}
+
+	flags.StringVar(&policyFile, "policy", "", "")
+	o.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	root := "."
+	if flags.NArg() > 0 {
+		root = flags.Arg(0)
+	}
+
+	if policyFile == "" {
+		var ok bool
+		policyFile, ok = findPolicyFile(root)
+		if !ok {
+			fmt.Fprintf(cli.errStream, "Policy file is not found: create %s or use -policy option\n", PolicyFileNames[0])
+			return ExitCodeError
+		}
+	}
+
+	policy, err := readPolicy(policyFile)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to read policy: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	pkgs, err := findVendoredPackages(root)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to find vendored packages: %s\n", err.Error())
+		return ExitCodeError
+	}
+	pkgs = append([]policyPackage{{name: ProjectPackage, dir: root}}, pkgs...)
+
+	corpus, err := cli.loadCorpus(&o)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to fetch LICENSE list: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	exceptions := cli.loadExceptions()
+
+	violations := 0
+	for _, pkg := range pkgs {
+		if d, err := detectLicense(pkg.dir, corpus, exceptions); err == nil {
+			pkg.key = policyID(d.Key, d.Exception)
+		} else {
+			Debugf("Failed to detect LICENSE of %s: %s", pkg.name, err.Error())
+		}
+
+		key := pkg.key
+		if key == "" {
+			key = "unknown"
+		}
+
+		reason := policy.evaluate(pkg.key)
+		if reason == "" {
+			Debugf("%s (%s) is allowed", pkg.name, key)
+			continue
+		}
+
+		if e, ok := policy.exception(pkg.name, pkg.key); ok {
+			fmt.Fprintf(cli.outStream, "EXCEPTION %s (%s): %s\n", pkg.name, key, e.Justification)
+			continue
+		}
+
+		violations++
+		fmt.Fprintf(cli.outStream, "VIOLATION %s (%s): %s\n", pkg.name, key, reason)
+	}
+
+	if violations > 0 {
+		fmt.Fprintf(cli.errStream, "====> %d of %d packages violate %s\n", violations, len(pkgs), policyFile)
+		return ExitCodeCheckFailed
+	}
+
+	fmt.Fprintf(cli.errStream, "====> All %d packages comply with %s\n", len(pkgs), policyFile)
+	return ExitCodeOK
+}
+
+var policyHelpText = `Usage: license policy check [option] [PATH]
+
+  Check LICENSE of the project in PATH (by default, current directory)
+  and each vendored package (directories which have LICENSE file in
+  vendor/ or third_party/) against the policy file. LICENSE is detected
+  like 'license detect'. It exits with non-zero status if any package
+  violates the policy.
+
+  The policy file is YAML (.license-policy.yaml in PATH by default).
+  Keys are the same as '-list' shows. The project itself is package '.'.
+
+    allow:
+      - mit
+      - apache-2.0
+    deny:
+      - agpl-3.0
+    exceptions:
+      - package: github.com/example/gpl-tool
+        license: gpl-3.0
+        justification: Used only for build and not distributed
+
+  If allow is empty, any LICENSE which is not denied is allowed.
+  LICENSE with exception (e.g., 'gpl-2.0 WITH classpath-exception-2.0')
+  matches its own entry first, then the entry of the LICENSE itself.
+  License of exception is optional.
+
+Options:
+
+  -policy=FILE        Path to the policy file.
+
+  Options to fetch LICENSE (e.g., -offline, -no-cache) are the same as
+  generating LICENSE. Templates are read from cache if available.
+`
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".license-policy.yaml")
+	policy := `allow: [MIT, apache-2.0, gpl-3.0, "GPL-2.0  WITH Classpath-exception-2.0"]
+deny: [agpl-3.0]
+exceptions:
+  - package: github.com/example/tool
+    license: gpl-3.0
+    justification: Used only for build
+`
+	if err := ioutil.WriteFile(path, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := readPolicy(path)
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	cases := []struct {
+		key       string
+		violation bool
+	}{
+		{"mit", false},
+		{"apache-2.0", false},
+		{"agpl-3.0", true},
+		{"bsd-3-clause", true},
+		{"gpl-2.0 with classpath-exception-2.0", false},
+		{"gpl-2.0", true},
+		{"gpl-2.0 with gcc-exception-3.1", true},
+		{"gpl-3.0 with gcc-exception-3.1", false},
+		{"agpl-3.0 with classpath-exception-2.0", true},
+		{"", true},
+	}
+
+	for _, tc := range cases {
+		if reason := p.evaluate(tc.key); (reason != "") != tc.violation {
+			t.Errorf("%q: expected violation %t, got %q", tc.key, tc.violation, reason)
+		}
+	}
+
+	if _, ok := p.exception("github.com/example/tool", "gpl-3.0"); !ok {
+		t.Errorf("expected exception for gpl-3.0")
+	}
+	if _, ok := p.exception("github.com/example/tool", "gpl-3.0 with gcc-exception-3.1"); !ok {
+		t.Errorf("expected exception for gpl-3.0 with gcc-exception-3.1")
+	}
+	if _, ok := p.exception("github.com/example/tool", "agpl-3.0"); ok {
+		t.Errorf("expected no exception for agpl-3.0")
+	}
+}
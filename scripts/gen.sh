@@ -17,7 +17,11 @@ mkdir ${OUTDIR}
 make build
 for key in $(./bin/license -list-keys); do
     ./bin/license -output=${OUTDIR}/${key} -no-cache ${key}
-    sleep 10s
+
+    # Unauthenticated requests are limited to 60 requests/hour
+    if [ -z "${GITHUB_TOKEN}" ]; then
+        sleep 10s
+    fi
 done
 
 ls ${OUTDIR}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/colorstring"
+)
+
+// ruleLabels are labels of rules (permissions, conditions and limitations)
+// used by GitHub API and choosealicense.com.
+// See https://github.com/github/choosealicense.com/blob/gh-pages/_data/rules.yml
+var ruleLabels = map[string]string{
+	"commercial-use": "Commercial use",
+	"modifications":  "Modification",
+	"distribution":   "Distribution",
+	"private-use":    "Private use",
+	"patent-use":     "Patent use",
+
+	"include-copyright":         "License and copyright notice",
+	"include-copyright--source": "License and copyright notice for source",
+	"document-changes":          "State changes",
+	"disclose-source":           "Disclose source",
+	"network-use-disclose":      "Network use is distribution",
+	"same-license":              "Same license",
+	"same-license--file":        "Same license (file)",
+	"same-license--library":     "Same license (library)",
+
+	"liability":     "Liability",
+	"warranty":      "Warranty",
+	"trademark-use": "Trademark use",
+}
+
+// ruleLabel returns human readable label of the rule.
+func ruleLabel(key string) string {
+	if label, ok := ruleLabels[key]; ok {
+		return label
+	}
+	return key
+}
+
+// wrapText wraps text by width and indents each line.
+func wrapText(text string, width int, indent string) string {
+	var buf bytes.Buffer
+	line := indent
+	for _, w := range strings.Fields(text) {
+		if len(line) > len(indent) && len(line)+1+len(w) > width {
+			buf.WriteString(strings.TrimRight(line, " ") + "\n")
+			line = indent
+		}
+		if len(line) > len(indent) {
+			line += " "
+		}
+		line += w
+	}
+	if len(line) > len(indent) {
+		buf.WriteString(line + "\n")
+	}
+	return buf.String()
+}
+
+// renderLicense renders metadata of LICENSE like choosealicense.com.
+// Permissions are green, conditions are blue and limitations are red.
+func renderLicense(l *License, color bool) string {
+	c := colorstring.Colorize{
+		Colors:  colorstring.DefaultColors,
+		Disable: !color,
+		Reset:   true,
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(c.Color(fmt.Sprintf("[bold]%s", l.Name)))
+	if l.SPDXID != "" {
+		fmt.Fprintf(&buf, " (%s, SPDX: %s)\n", l.Key, l.SPDXID)
+	} else {
+		fmt.Fprintf(&buf, " (%s)\n", l.Key)
+	}
+
+	// Featured LICENSE is recommended on choosealicense.com
+	if l.Featured {
+		buf.WriteString(c.Color("  [yellow]Featured") + "\n")
+	}
+
+	if l.Description != "" {
+		buf.WriteString("\n")
+		buf.WriteString(wrapText(l.Description, 76, "  "))
+	}
+
+	for _, section := range []struct {
+		title string
+		color string
+		mark  string
+		keys  []string
+	}{
+		{"Permissions", "green", "+", l.Permissions},
+		{"Conditions", "blue", "i", l.Conditions},
+		{"Limitations", "red", "-", l.Limitations},
+	} {
+		if len(section.keys) == 0 {
+			continue
+		}
+
+		buf.WriteString("\n")
+		buf.WriteString(c.Color(fmt.Sprintf("  [%s][bold]%s", section.color, section.title)) + "\n")
+		for _, k := range section.keys {
+			buf.WriteString(c.Color(fmt.Sprintf("    [%s]%s[reset] %s", section.color, section.mark, ruleLabel(k))) + "\n")
+		}
+	}
+
+	if l.Implementation != "" {
+		buf.WriteString("\n")
+		buf.WriteString(c.Color("  [bold]How to apply") + "\n")
+		buf.WriteString(wrapText(l.Implementation, 76, "    "))
+	}
+
+	return buf.String()
+}
+
+// getLicenseMetadata returns LICENSE with its metadata. Cache has only
+// body, so metadata is fetched from source if it's not bundled.
+func (cli *CLI) getLicenseMetadata(key string, o *options) (*License, error) {
+	license, cached, err := cli.getLicense(key, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached && !hasRules(license) {
+		if l, err := cli.source.Get(key); err == nil {
+			license = l
+		} else {
+			Debugf("Failed to get metadata: %s", err.Error())
+		}
+	}
+	return license, nil
+}
+
+// runShow runs `license show` command. It shows metadata of LICENSE.
+func (cli *CLI) runShow(args []string) int {
+	var (
+		jsonFormat bool
+		noColor    bool
+		o          options
+	)
+
+	flags := flag.NewFlagSet(Name+" show", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, showHelpText)
+	}
+
+	flags.BoolVar(&jsonFormat, "json", false, "")
+	flags.BoolVar(&noColor, "no-color", false, "")
+	o.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintf(cli.errStream, "LICENSE key is required\n")
+		return ExitCodeError
+	}
+	key := strings.ToLower(flags.Arg(0))
+
+	license, err := cli.getLicenseMetadata(key, &o)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to get LICENSE: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	if jsonFormat {
+		enc := json.NewEncoder(cli.outStream)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(license); err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to print LICENSE: %s\n", err.Error())
+			return ExitCodeError
+		}
+		return ExitCodeOK
+	}
+
+	color := !noColor
+	if f, ok := cli.outStream.(*os.File); !ok || !isTerminal(f) {
+		color = false
+	}
+
+	fmt.Fprint(cli.outStream, renderLicense(license, color))
+	return ExitCodeOK
+}
+
+var showHelpText = `Usage: license show [option] KEY
+
+  Show description, permissions (green), conditions (blue) and
+  limitations (red) of LICENSE like http://choosealicense.com/
+  Featured LICENSE (recommended there) is marked as 'Featured'.
+
+Options:
+
+  -json               Print LICENSE and its metadata as JSON.
+
+  -no-color           Disable color output. It's disabled when output
+                      is not terminal.
+
+  Options to fetch LICENSE (e.g., -offline, -no-cache) are the same as
+  generating LICENSE.
+`
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderLicense(t *testing.T) {
+	license, err := newBundledSource().Get("mit")
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	got := renderLicense(license, false)
+	for _, expected := range []string{
+		"MIT License (mit, SPDX: MIT)\n  Featured\n",
+		"  Permissions\n    + Commercial use\n",
+		"  Conditions\n    i License and copyright notice\n",
+		"  Limitations\n    - Liability\n",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("expected %q to contain %q", got, expected)
+		}
+	}
+
+	license, err = newBundledSource().Get("bsd-3-clause")
+	if err != nil {
+		t.Fatalf("should not fail: %s", err)
+	}
+
+	if got := renderLicense(license, false); strings.Contains(got, "Featured") {
+		t.Errorf("expected %q not to contain %q", got, "Featured")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// License is LICENSE template and its metadata provided by LicenseSource.
+type License struct {
+	// Key is the name used when fetching LICENSE (e.g., "mit").
+	// Every key must be lower case.
+	Key  string `json:"key"`
+	Name string `json:"name"`
+
+	SPDXID         string `json:"spdx_id"`
+	Description    string `json:"description"`
+	Implementation string `json:"implementation"`
+	Featured       bool   `json:"featured"`
+
+	// Permissions, Conditions and Limitations are the rules
+	// of the LICENSE like http://choosealicense.com/
+	Permissions []string `json:"permissions"`
+	Conditions  []string `json:"conditions"`
+	Limitations []string `json:"limitations"`
+
+	// Body is the LICENSE text. It may be empty when License is
+	// returned by LicenseSource.List.
+	Body string `json:"body"`
+}
+
+// LicenseSource is the interface to provide LICENSE templates.
+// GitHub API is the default one but it can be replaced by another
+// implementation, e.g., internal mirror, local directory or test fake.
+type LicenseSource interface {
+	// List returns all LICENSE available in the source.
+	List() ([]*License, error)
+
+	// Get returns a LICENSE with its body and metadata by key.
+	Get(key string) (*License, error)
+}
+
+// notFoundError is the error when LicenseSource doesn't have LICENSE.
+// It's less important than other errors (e.g., rate limit of GitHub API)
+// because the other sources may have it.
+type notFoundError struct {
+	msg string
+}
+
+func (e *notFoundError) Error() string {
+	return e.msg
+}
+
+// newNotFoundError returns notFoundError with formatted message.
+func newNotFoundError(format string, a ...interface{}) error {
+	return &notFoundError{msg: fmt.Sprintf(format, a...)}
+}
+
+// isNotFound returns true if err is notFoundError.
+func isNotFound(err error) bool {
+	var e *notFoundError
+	return errors.As(err, &e)
+}
+
+// preferError returns the error which should be shown to user from
+// the current one and err. The first error wins but errors other than
+// notFoundError are preferred since they tell what's wrong.
+func preferError(current, err error) error {
+	if current == nil || (isNotFound(current) && !isNotFound(err)) {
+		return err
+	}
+	return current
+}
+
+// warnSource is LicenseSource which warns user of its errors except
+// notFoundError (e.g., rate limit of GitHub API). In chainSource, they
+// are hidden when the next source (e.g., bundled one) is used instead.
+type warnSource struct {
+	LicenseSource
+
+	// name is the name of source shown in warning, e.g., "GitHub API"
+	name      string
+	errStream io.Writer
+}
+
+// List returns list of LICENSE and warns its error.
+func (s *warnSource) List() ([]*License, error) {
+	list, err := s.LicenseSource.List()
+	if err != nil && !isNotFound(err) {
+		fmt.Fprintf(s.errStream, "Warning: failed to list LICENSE from %s, fall back to the next source: %s\n", s.name, err.Error())
+	}
+	return list, err
+}
+
+// Get returns LICENSE and warns its error.
+func (s *warnSource) Get(key string) (*License, error) {
+	l, err := s.LicenseSource.Get(key)
+	if err != nil && !isNotFound(err) {
+		fmt.Fprintf(s.errStream, "Warning: failed to get LICENSE %q from %s, fall back to the next source: %s\n", key, s.name, err.Error())
+	}
+	return l, err
+}
+
+// chainSource is LicenseSource which combines multiple sources.
+// List merges LICENSE of all sources (the former source wins when keys
+// are duplicated) and Get returns the LICENSE from the first source
+// which has it. It is used for falling back to other sources when
+// one of them is not available (e.g., no network).
+type chainSource []LicenseSource
+
+// List returns merged list of LICENSE. It returns error only when
+// all sources are failed (see preferError).
+func (c chainSource) List() ([]*License, error) {
+	var (
+		list    []*License
+		lastErr error
+		success bool
+	)
+
+	seen := make(map[string]bool)
+	for _, s := range c {
+		l, err := s.List()
+		if err != nil {
+			Debugf("Failed to list LICENSE: %s", err.Error())
+			lastErr = preferError(lastErr, err)
+			continue
+		}
+		success = true
+
+		for _, license := range l {
+			if seen[license.Key] {
+				continue
+			}
+			seen[license.Key] = true
+			list = append(list, license)
+		}
+	}
+
+	if !success && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return list, nil
+}
+
+// Get returns LICENSE from the first source which has it.
+// If all sources are failed, it returns the error of the first source
+// which is not notFoundError if any (see preferError).
+func (c chainSource) Get(key string) (*License, error) {
+	var firstErr error
+	for _, s := range c {
+		l, err := s.Get(key)
+		if err != nil {
+			Debugf("Failed to get LICENSE %q: %s", key, err.Error())
+			firstErr = preferError(firstErr, err)
+			continue
+		}
+		return l, nil
+	}
+
+	if firstErr == nil {
+		firstErr = fmt.Errorf("no LICENSE source")
+	}
+	return nil, firstErr
+}
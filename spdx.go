@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SPDXTimeout is timeout of fetching SPDX data from URL.
+var SPDXTimeout = 30 * time.Second
+
+// spdxSource is LicenseSource which reads SPDX License List data
+// (https://github.com/spdx/license-list-data) in JSON format.
+// base is local directory or URL which contains licenses.json and
+// details/ID.json (e.g., json directory of license-list-data).
+type spdxSource struct {
+	base   string
+	client *http.Client
+
+	// list is the cache of licenses.json
+	list []spdxLicense
+}
+
+// spdxList is the format of licenses.json
+type spdxList struct {
+	Version  string        `json:"licenseListVersion"`
+	Licenses []spdxLicense `json:"licenses"`
+}
+
+// spdxLicense is the format of each license in licenses.json
+type spdxLicense struct {
+	ID         string `json:"licenseId"`
+	Name       string `json:"name"`
+	Deprecated bool   `json:"isDeprecatedLicenseId"`
+}
+
+// spdxDetails is the format of details/ID.json
+type spdxDetails struct {
+	ID       string `json:"licenseId"`
+	Name     string `json:"name"`
+	Text     string `json:"licenseText"`
+	Template string `json:"standardLicenseTemplate"`
+	Comments string `json:"licenseComments"`
+}
+
+// newSPDXSource returns LICENSE source of SPDX License List data.
+func newSPDXSource(base string) *spdxSource {
+	return &spdxSource{
+		base:   base,
+		client: &http.Client{Timeout: SPDXTimeout},
+	}
+}
+
+// isRemote returns true when data is fetched from URL.
+func (s *spdxSource) isRemote() bool {
+	return strings.HasPrefix(s.base, "http://") || strings.HasPrefix(s.base, "https://")
+}
+
+// readFile reads file (relative path from base) from local directory
+// or URL. It returns os.ErrNotExist when file is not found.
+func (s *spdxSource) readFile(name string) ([]byte, error) {
+	if !s.isRemote() {
+		return ioutil.ReadFile(filepath.Join(s.base, filepath.FromSlash(name)))
+	}
+
+	url := strings.TrimSuffix(s.base, "/") + "/" + name
+	Debugf("Fetch SPDX data: %s", url)
+	res, err := s.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, os.ErrNotExist
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid status code from %s: %s", url, res.Status)
+	}
+
+	return ioutil.ReadAll(res.Body)
+}
+
+// licenses reads licenses.json (only once).
+func (s *spdxSource) licenses() ([]spdxLicense, error) {
+	if s.list != nil {
+		return s.list, nil
+	}
+
+	b, err := s.readFile("licenses.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var list spdxList
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse licenses.json: %s", err.Error())
+	}
+	Debugf("SPDX License List version: %s", list.Version)
+
+	s.list = list.Licenses
+	return s.list, nil
+}
+
+// List returns all (not deprecated) licenses in SPDX License List.
+// Key is SPDX ID in lower case. Body of each License is empty.
+func (s *spdxSource) List() ([]*License, error) {
+	licenses, err := s.licenses()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*License, 0, len(licenses))
+	for _, l := range licenses {
+		if l.Deprecated {
+			continue
+		}
+
+		list = append(list, &License{
+			Key:    spdxKey(l.ID),
+			Name:   l.Name,
+			SPDXID: l.ID,
+		})
+	}
+
+	return list, nil
+}
+
+// Get reads details of license by key. key is SPDX ID in lower case.
+// When it's not found, key with "-only" suffix is also tried, e.g.,
+// gpl-3.0 key of GitHub is GPL-3.0-only in SPDX. Deprecated ID is
+// used only when there is no other choice.
+func (s *spdxSource) Get(key string) (*License, error) {
+	licenses, err := s.licenses()
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := lookupSPDXID(licenses, key, false)
+	if !ok {
+		id, ok = lookupSPDXID(licenses, key+"-only", false)
+	}
+	if !ok {
+		id, ok = lookupSPDXID(licenses, key, true)
+	}
+	if !ok {
+		return nil, newNotFoundError("license %q is not found in SPDX License List", key)
+	}
+
+	// license-list-data has details in details directory
+	// but https://spdx.org/licenses/ has them in the same directory.
+	b, err := s.readFile("details/" + id + ".json")
+	if os.IsNotExist(err) {
+		b, err = s.readFile(id + ".json")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var details spdxDetails
+	if err := json.Unmarshal(b, &details); err != nil {
+		return nil, fmt.Errorf("failed to parse details of %s: %s", id, err.Error())
+	}
+
+	body := details.Text
+	if details.Template != "" {
+		body = renderSPDXTemplate(details.Template)
+	}
+
+	return &License{
+		Key:         key,
+		Name:        details.Name,
+		SPDXID:      details.ID,
+		Description: details.Comments,
+		Body:        body,
+	}, nil
+}
+
+// lookupSPDXID finds SPDX ID by key (case insensitive).
+func lookupSPDXID(licenses []spdxLicense, key string, deprecated bool) (string, bool) {
+	for _, l := range licenses {
+		if l.Deprecated != deprecated {
+			continue
+		}
+		if strings.EqualFold(l.ID, key) {
+			return l.ID, true
+		}
+	}
+	return "", false
+}
+
+// spdxKey converts SPDX ID to key which is used in CLI.
+func spdxKey(id string) string {
+	return strings.ToLower(id)
+}
+
+// spdxVarReg matches variable markup in SPDX license template, e.g.,
+// <<var;name="copyright";original="Copyright (c) <year> <owner>";match=".+">>
+var spdxVarReg = regexp.MustCompile(`(?s)<<var;name="?(.*?)"?;original="(.*?)";match="(.*?)">>`)
+
+// spdxOptionalReg matches optional markup in SPDX license template.
+var spdxOptionalReg = regexp.MustCompile(`<<(begin|end)Optional(;[^>]*)?>>`)
+
+// spdxCopyright is the copyright line which replaces copyright variable
+// of SPDX template. Placeholders in it are replaced by CLI.
+const spdxCopyright = "Copyright (c) [year] [fullname]"
+
+var (
+	// spdxHolderVarReg matches name of variable for copyright holder,
+	// e.g., copyrightHolder0 of ISC or organizationClause3 of BSD-3-Clause
+	spdxHolderVarReg = regexp.MustCompile(`(?i)holder|owner|organization|author`)
+
+	// spdxYearVarReg matches name of variable for year
+	spdxYearVarReg = regexp.MustCompile(`(?i)year`)
+)
+
+// renderSPDXTemplate converts SPDX license template markup to plain text.
+// Copyright variable is replaced with the line with placeholders, holder
+// and year variables are replaced with their placeholders and the other
+// variables are replaced with its original text. Optional text is kept.
+func renderSPDXTemplate(template string) string {
+	body := spdxVarReg.ReplaceAllStringFunc(template, func(v string) string {
+		m := spdxVarReg.FindStringSubmatch(v)
+		name, original := m[1], m[2]
+		switch {
+		case name == "copyright":
+			return spdxCopyright
+		case spdxHolderVarReg.MatchString(name):
+			return commonPlaceholders[FieldHolder][0]
+		case spdxYearVarReg.MatchString(name):
+			return commonPlaceholders[FieldYear][0]
+		}
+		return original
+	})
+
+	return spdxOptionalReg.ReplaceAllString(body, "")
+}
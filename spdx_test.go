@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSPDXSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "details"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"licenses.json": `{"licenseListVersion": "3.0", "licenses": [
+  {"licenseId": "ISC", "name": "ISC License", "isDeprecatedLicenseId": false},
+  {"licenseId": "BSD-3-Clause", "name": "BSD 3-Clause \"New\" or \"Revised\" License", "isDeprecatedLicenseId": false},
+  {"licenseId": "GPL-3.0-only", "name": "GNU General Public License v3.0 only", "isDeprecatedLicenseId": false},
+  {"licenseId": "GPL-3.0", "name": "GNU General Public License v3.0 only", "isDeprecatedLicenseId": true}
+]}`,
+		"details/ISC.json": `{"licenseId": "ISC", "name": "ISC License",
+  "licenseText": "ISC License\n\nCopyright (c) <year> <owner>\n",
+  "standardLicenseTemplate": "<<beginOptional>>ISC License<<endOptional>>\n\n<<var;name=\"copyright\";original=\"Copyright (c) <year> <owner>\";match=\".{0,5000}\">>\n\nTHE SOFTWARE IS PROVIDED \"AS IS\" AND <<var;name=\"copyrightHolder0\";original=\"THE AUTHOR\";match=\"ISC|THE AUTHOR\">> DISCLAIMS\n"}`,
+		// Variables of details/BSD-3-Clause.json of license-list-data (text is shortened)
+		"details/BSD-3-Clause.json": `{"licenseId": "BSD-3-Clause", "name": "BSD 3-Clause \"New\" or \"Revised\" License",
+  "standardLicenseTemplate": "<<beginOptional>>BSD 3-Clause License\n\n<<endOptional>><<var;name=\"copyright\";original=\"Copyright (c) <year> <owner>. All rights reserved.\";match=\".{0,5000}\">>\n\nRedistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:\n\n<<var;name=\"bullet\";original=\"3.\";match=\".{0,20}\">> Neither the name of <<var;name=\"organizationClause3\";original=\"the copyright holder\";match=\".+\">> nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.\n\nTHIS SOFTWARE IS PROVIDED BY <<var;name=\"copyrightHolderAsIs\";original=\"THE COPYRIGHT HOLDERS AND CONTRIBUTORS\";match=\".+\">> \"AS IS\". IN NO EVENT SHALL <<var;name=\"copyrightHolderLiability\";original=\"THE COPYRIGHT HOLDER OR CONTRIBUTORS\";match=\".+\">> BE LIABLE.\n"}`,
+		"details/GPL-3.0-only.json": `{"licenseId": "GPL-3.0-only", "name": "GNU General Public License v3.0 only", "licenseText": "GNU GENERAL PUBLIC LICENSE\n"}`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := newSPDXSource(dir)
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].Key != "isc" || list[2].Key != "gpl-3.0-only" {
+		t.Errorf("unexpected list: %#v", list)
+	}
+
+	l, err := s.Get("isc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "ISC License\n\nCopyright (c) [year] [fullname]\n\nTHE SOFTWARE IS PROVIDED \"AS IS\" AND [fullname] DISCLAIMS\n"
+	if l.Body != expected {
+		t.Errorf("expected %q to eq %q", l.Body, expected)
+	}
+
+	// Holder and year are replaced in every variable
+	l, err = s.Get("bsd-3-clause")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cli := &CLI{outStream: ioutil.Discard, errStream: ioutil.Discard, nonInteractive: true}
+	body, unresolved := cli.ReplacePlaceholders(l.Body, l.Key, placeholderOptions{year: "2015", holders: []string{"tcnksm"}})
+	if len(unresolved) != 0 {
+		t.Fatalf("expected %v to be empty", unresolved)
+	}
+
+	for _, s := range []string{"Copyright (c) 2015 tcnksm", "3. Neither the name of tcnksm nor", "PROVIDED BY tcnksm \"AS IS\"", "SHALL tcnksm BE LIABLE"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("expected %q to contain %q", body, s)
+		}
+	}
+
+	l, err = s.Get("gpl-3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.SPDXID != "GPL-3.0-only" {
+		t.Errorf("expected %q to eq %q", l.SPDXID, "GPL-3.0-only")
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yearsReg matches years in copyright line, e.g., "2015", "2015-2020",
+// "2015 - 2020" or "2015, 2016, 2018".
+var yearsReg = regexp.MustCompile(`(?:19|20)\d{2}(?:\s*(?:,|-|–)\s*(?:19|20)\d{2})*`)
+
+// rangeSepReg matches the separator of year range.
+var rangeSepReg = regexp.MustCompile(`\s*(?:-|–)\s*`)
+
+// licenseStewards are copyright holders of LICENSE text itself.
+// Their copyright lines must not be updated.
+var licenseStewards = []string{
+	"Free Software Foundation",
+}
+
+// updateYearInLine extends years in copyright line to year. The style
+// of years is kept: a single year becomes range ("2015" to "2015-2020"),
+// the end of range is replaced ("2015 - 2019" to "2015 - 2020") and year
+// is appended to list ("2015, 2017" to "2015, 2017, 2020").
+// It returns false if the line is not changed.
+func updateYearInLine(line string, year int) (string, bool) {
+	if !copyrightLineReg.MatchString(line) {
+		return line, false
+	}
+
+	for _, s := range licenseStewards {
+		if strings.Contains(line, s) {
+			return line, false
+		}
+	}
+
+	loc := yearsReg.FindStringIndex(line)
+	if loc == nil {
+		return line, false
+	}
+	years := line[loc[0]:loc[1]]
+
+	// The last element of the list, it may be range
+	last := years
+	if i := strings.LastIndex(years, ","); i >= 0 {
+		last = strings.TrimLeft(years[i+1:], " \t")
+	}
+	prefix := years[:len(years)-len(last)]
+
+	lastYear, err := strconv.Atoi(last[len(last)-4:])
+	if err != nil || lastYear >= year {
+		return line, false
+	}
+
+	var updated string
+	switch {
+	case rangeSepReg.MatchString(last):
+		updated = prefix + last[:len(last)-4] + strconv.Itoa(year)
+	case prefix != "":
+		// Separator of list, e.g., ", "
+		sep := prefix[strings.LastIndex(prefix, ","):]
+		updated = years + sep + strconv.Itoa(year)
+	default:
+		updated = years + "-" + strconv.Itoa(year)
+	}
+
+	return line[:loc[0]] + updated + line[loc[1]:], true
+}
+
+// allRightsReg matches "All rights reserved" after copyright holder.
+var allRightsReg = regexp.MustCompile(`(?i)all rights reserved\.?`)
+
+// copyrightHolder returns the holder of copyright line, e.g., "tcnksm"
+// of "Copyright (c) 2015 tcnksm. All rights reserved.". It returns an
+// empty string if the line is not a copyright line with years.
+func copyrightHolder(line string) string {
+	loc := yearsReg.FindStringIndex(line)
+	if loc == nil || !copyrightLineReg.MatchString(line) {
+		return ""
+	}
+	holder := allRightsReg.ReplaceAllString(line[loc[1]:], "")
+	return strings.Trim(holder, " \t.,;:*/#-")
+}
+
+// licenseHolders returns copyright holders found in LICENSE file.
+// Holders of LICENSE text itself (licenseStewards) are excluded.
+func licenseHolders(path string) ([]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var holders []string
+	for _, l := range strings.Split(string(content), "\n") {
+		h := copyrightHolder(l)
+		if h != "" && !contains(holders, h) && !contains(licenseStewards, h) {
+			holders = append(holders, h)
+		}
+	}
+	return holders, nil
+}
+
+// hasHolder returns true if copyright line has one of holders.
+// Holders are compared case insensitively.
+func hasHolder(line string, holders []string) bool {
+	for _, h := range holders {
+		if strings.Contains(strings.ToLower(line), strings.ToLower(h)) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateYear extends years in copyright lines of content. If n is
+// positive, only the first n lines are updated. If holders is not
+// empty, only copyright lines of them are updated.
+func updateYear(content []byte, year, n int, holders []string) ([]byte, bool) {
+	lines := strings.Split(string(content), "\n")
+
+	changed := false
+	for i, l := range lines {
+		if n > 0 && i >= n {
+			break
+		}
+		if len(holders) > 0 && !hasHolder(l, holders) {
+			continue
+		}
+		if updated, ok := updateYearInLine(l, year); ok {
+			lines[i] = updated
+			changed = true
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), changed
+}
+
+// runUpdateYear runs `license update-year` command. It updates copyright
+// year in LICENSE file and optionally in license headers of source files.
+func (cli *CLI) runUpdateYear(args []string) int {
+	var (
+		headers bool
+		dryRun  bool
+		o       options
+	)
+
+	flags := flag.NewFlagSet(Name+" update-year", flag.ContinueOnError)
+	flags.SetOutput(cli.errStream)
+	flags.Usage = func() {
+		fmt.Fprint(cli.errStream, updateYearHelpText)
+	}
+
+	flags.BoolVar(&headers, "headers", false, "")
+	flags.BoolVar(&dryRun, "dry-run", false, "")
+	o.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitCodeError
+	}
+
+	if err := cli.setup(flags, &o); err != nil {
+		fmt.Fprintf(cli.errStream, "Failed to setup: %s\n", err.Error())
+		return ExitCodeError
+	}
+
+	// Update to the end of years range (e.g., -year=git)
+	y := resolveYear(o.year)
+	if loc := rangeSepReg.FindStringIndex(y); loc != nil {
+		y = y[loc[1]:]
+	}
+
+	year, err := strconv.Atoi(y)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "Invalid year %q: it must be a number\n", o.year)
+		return ExitCodeError
+	}
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	update := func(path string, n int, holders []string) (bool, error) {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+
+		newContent, changed := updateYear(content, year, n, holders)
+		if !changed {
+			Debugf("Skip %s: copyright year is up to date", path)
+			return false, nil
+		}
+
+		fmt.Fprintf(cli.errStream, "----> Update copyright year in %s\n", path)
+		if dryRun {
+			return true, nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		return true, ioutil.WriteFile(path, newContent, info.Mode())
+	}
+
+	// Copyright holders of the project. Headers may have copyright
+	// lines of others (e.g., copied code), they must not be updated.
+	holders := append([]string{}, o.holders...)
+
+	updated := 0
+	if path, ok := findLicenseFile(dir); ok {
+		found, err := licenseHolders(path)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to read LICENSE: %s\n", err.Error())
+			return ExitCodeError
+		}
+		for _, h := range found {
+			if !contains(holders, h) {
+				holders = append(holders, h)
+			}
+		}
+
+		changed, err := update(path, 0, nil)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to update copyright year: %s\n", err.Error())
+			return ExitCodeError
+		}
+		if changed {
+			updated++
+		}
+	} else if !headers {
+		fmt.Fprintf(cli.errStream, "LICENSE file is not found in %s\n", dir)
+		return ExitCodeError
+	}
+
+	if headers {
+		if len(holders) == 0 {
+			fmt.Fprintf(cli.errStream, "Copyright holder is not found in LICENSE: provide it by -author\n")
+			return ExitCodeError
+		}
+		Debugf("Copyright holders: %s", strings.Join(holders, ", "))
+
+		err := walkSourceFiles([]string{dir}, func(path string, style commentStyle) error {
+			changed, err := update(path, HeaderScanLines, holders)
+			if changed {
+				updated++
+			}
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Failed to update copyright year: %s\n", err.Error())
+			return ExitCodeError
+		}
+	}
+
+	msg := fmt.Sprintf("====> Updated copyright year to %d in %d files", year, updated)
+	if dryRun {
+		msg += " (dry-run)"
+	}
+	fmt.Fprintln(cli.errStream, msg)
+
+	return ExitCodeOK
+}
+
+var updateYearHelpText = `Usage: license update-year [option] [DIR]
+
+  Update copyright year in LICENSE file in DIR (by default, current
+  directory) to the current year. The style of years is kept:
+
+    Copyright (c) 2015          -> Copyright (c) 2015-2020
+    Copyright (c) 2015 - 2019   -> Copyright (c) 2015 - 2020
+    Copyright (c) 2015, 2017    -> Copyright (c) 2015, 2017, 2020
+
+Options:
+
+  -year=YEAR          Update to YEAR instead of the current year. If
+                      YEAR is 'git', the year of the last commit is used.
+
+  -headers            Also update license headers of source files in DIR.
+                      Only copyright lines of the holders (-author or
+                      ones found in LICENSE) are updated.
+
+  -author=NAME        Copyright holder of license headers. It can be
+                      repeated.
+
+  -dry-run            Show files to be changed without changing them.
+`
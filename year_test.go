@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestUpdateYearInLine(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected string
+		changed  bool
+	}{
+		{"Copyright (c) 2015 tcnksm", "Copyright (c) 2015-2026 tcnksm", true},
+		{"// Copyright 2015-2020 Google Inc.", "// Copyright 2015-2026 Google Inc.", true},
+		{"# Copyright (C) 2015 - 2020 tcnksm", "# Copyright (C) 2015 - 2026 tcnksm", true},
+		{"Copyright (c) 2015, 2017 tcnksm", "Copyright (c) 2015, 2017, 2026 tcnksm", true},
+		{"Copyright (c) 2015, 2017-2020 tcnksm", "Copyright (c) 2015, 2017-2026 tcnksm", true},
+		{"Copyright (c) 2015-2026 tcnksm", "Copyright (c) 2015-2026 tcnksm", false},
+		{"Copyright (C) 2007 Free Software Foundation, Inc.", "Copyright (C) 2007 Free Software Foundation, Inc.", false},
+		{"Released in 2015", "Released in 2015", false},
+	}
+
+	for _, tc := range cases {
+		got, changed := updateYearInLine(tc.line, 2026)
+		if got != tc.expected || changed != tc.changed {
+			t.Errorf("expected %q (%t) to eq %q (%t)", got, changed, tc.expected, tc.changed)
+		}
+	}
+}
+
+func TestUpdateYear_holders(t *testing.T) {
+	content := "// Copyright 2015 tcnksm\n// Copyright 2010 Foo Inc.\n\npackage main\n"
+
+	got, changed := updateYear([]byte(content), 2026, HeaderScanLines, []string{"TCNKSM"})
+	expected := "// Copyright 2015-2026 tcnksm\n// Copyright 2010 Foo Inc.\n\npackage main\n"
+	if string(got) != expected || !changed {
+		t.Errorf("expected %q (%t) to eq %q", string(got), changed, expected)
+	}
+
+	// Copyright line of foreign holder is kept
+	content = "// Copyright 2010 Foo Inc.\n\npackage foo\n"
+	if got, changed := updateYear([]byte(content), 2026, HeaderScanLines, []string{"tcnksm"}); changed {
+		t.Errorf("expected %q not to be changed", string(got))
+	}
+}
+
+func TestCopyrightHolder(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected string
+	}{
+		{"Copyright (c) 2015 tcnksm", "tcnksm"},
+		{"Copyright 2015-2020 Google Inc. All rights reserved.", "Google Inc"},
+		{"/* Copyright (C) 2015, 2017 Taichi Nakashima */", "Taichi Nakashima"},
+		{"Released in 2015", ""},
+	}
+
+	for _, tc := range cases {
+		if got := copyrightHolder(tc.line); got != tc.expected {
+			t.Errorf("expected %q to eq %q", got, tc.expected)
+		}
+	}
+}